@@ -10,6 +10,7 @@ function seed(dbName, user, password) {
   db.createCollection("roles");
 
   db.api_keys.insert({
+    // hashed by the server on startup
     key: "1D3F2DD1A5DE725DD4DF1D82BBB37",
     permissions: ["GENERAL"],
     comments: ["To be used by the xyz vendor"],
@@ -54,6 +55,7 @@ function seed(dbName, user, password) {
       .find({})
       .toArray()
       .map((role) => role._id),
+    verified: true,
     status: true,
     createdAt: new Date(),
     updatedAt: new Date(),
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Generate a key pair for the token signing alg: RS256, ES256 or EdDSA
+func generateKeyPair(alg string) (*pem.Block, crypto.PublicKey, error) {
+	switch alg {
+	case "RS256":
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := privateKey.Validate(); err != nil {
+			return nil, nil, err
+		}
+		block := &pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+		}
+		return block, &privateKey.PublicKey, nil
+
+	case "ES256":
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		der, err := x509.MarshalECPrivateKey(privateKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}, &privateKey.PublicKey, nil
+
+	case "EdDSA":
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: der}, publicKey, nil
+
+	default:
+		return nil, nil, fmt.Errorf("alg %q is not supported, use RS256, ES256 or EdDSA", alg)
+	}
+}
+
+func savePEMBlockToFile(block *pem.Block, filename string) error {
+	// Create a new file for the key
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Write key to the file
+	if err := pem.Encode(file, block); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func savePublicKeyToFile(publicKey crypto.PublicKey, filename string) error {
+	// Marshal public key to DER format
+	derBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	// Encode public key to PEM format
+	return savePEMBlockToFile(&pem.Block{Type: "PUBLIC KEY", Bytes: derBytes}, filename)
+}
+
+// Move the current public key to the retired keys, the server keeps verifying
+// the tokens it signed while the new key signs the new tokens
+func retirePublicKey(publicKeyFile string, retiredDir string) error {
+	if _, err := os.Stat(publicKeyFile); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := os.MkdirAll(retiredDir, 0o755); err != nil {
+		return err
+	}
+
+	retiredFile := filepath.Join(retiredDir, fmt.Sprintf("public-%d.pem", time.Now().Unix()))
+	if err := os.Rename(publicKeyFile, retiredFile); err != nil {
+		return err
+	}
+
+	fmt.Println("Public key retired to", retiredFile)
+	return nil
+}
+
+func main() {
+	// go run .tools/keygen/keygen.go -alg ES256 -rotate
+	alg := flag.String("alg", "RS256", "token signing alg: RS256, ES256 or EdDSA")
+	rotate := flag.Bool("rotate", false, "retire the current key pair instead of replacing it")
+	flag.Parse()
+
+	// Generate key pair
+	privateBlock, publicKey, err := generateKeyPair(*alg)
+	if err != nil {
+		fmt.Println("Error generating key pair:", err)
+		return
+	}
+
+	if *rotate {
+		err := retirePublicKey("keys/public.pem", "keys/retired")
+		if err != nil {
+			fmt.Println("Error retiring public key:", err)
+			return
+		}
+	}
+
+	// Save private key to file
+	err = savePEMBlockToFile(privateBlock, "keys/private.pem")
+	if err != nil {
+		fmt.Println("Error saving private key:", err)
+		return
+	}
+
+	// Save public key to file
+	err = savePublicKeyToFile(publicKey, "keys/public.pem")
+	if err != nil {
+		fmt.Println("Error saving public key:", err)
+		return
+	}
+
+	fmt.Println(*alg, "key pair generated and saved to keys/private.pem and keys/public.pem")
+}
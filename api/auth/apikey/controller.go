@@ -0,0 +1,121 @@
+package apikey
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/api/auth"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	coredto "github.com/unusualcodeorg/goserve/arch/dto"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+)
+
+type controller struct {
+	network.BaseController
+	common.ContextPayload
+	permissionProvider network.PermissionProvider
+	service            auth.Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	permissionProvider network.PermissionProvider,
+	service auth.Service,
+) network.Controller {
+	return &controller{
+		BaseController:     network.NewBaseController("/auth/apikey", authProvider, authorizeProvider),
+		ContextPayload:     common.NewContextPayload(),
+		permissionProvider: permissionProvider,
+		service:            service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	adminKey := network.ApiPermission{
+		Permissions: []string{string(model.AdminPermission)},
+	}
+
+	group.Use(c.permissionProvider.Middleware(adminKey), c.Authentication(), c.Authorization(string(userModel.RoleCodeAdmin)))
+	group.POST("/", c.issueApiKeyHandler)
+	group.GET("/", c.getApiKeysHandler)
+	group.PUT("/revoke/id/:id", c.revokeApiKeyHandler)
+	group.POST("/rotate/id/:id", c.rotateApiKeyHandler)
+}
+
+func (c *controller) issueApiKeyHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptyCreateApiKey())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	data, err := c.service.IssueApiKey(body, user)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("api key issued successfully", data)
+}
+
+func (c *controller) getApiKeysHandler(ctx *gin.Context) {
+	pagination, err := network.ReqQuery(ctx, coredto.EmptyPagination())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	data, err := c.service.GetPaginatedApiKeys(pagination)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("success", data)
+}
+
+func (c *controller) revokeApiKeyHandler(ctx *gin.Context) {
+	mongoId, err := network.ReqParams(ctx, coredto.EmptyMongoId())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	err = c.service.RevokeApiKey(mongoId.ID, user)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessMsgResponse("api key revoked successfully")
+}
+
+func (c *controller) rotateApiKeyHandler(ctx *gin.Context) {
+	mongoId, err := network.ReqParams(ctx, coredto.EmptyMongoId())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	body, err := network.ReqBody(ctx, dto.EmptyRotateApiKey())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	data, err := c.service.RotateApiKey(mongoId.ID, body, user)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("api key rotated successfully", data)
+}
@@ -0,0 +1,127 @@
+package apikey
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/unusualcodeorg/goserve/api/auth"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func mockProviders(user *userModel.User) (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, user)
+		ctx.Next()
+	}))
+
+	mockAuthzProvider := new(network.MockAuthorizationProvider)
+	mockAuthzProvider.On("Middleware", []string{string(userModel.RoleCodeAdmin)}).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	return mockAuthProvider, mockAuthzProvider
+}
+
+func mockPermissionProvider() *network.MockPermissionProvider {
+	mockPermissionProvider := new(network.MockPermissionProvider)
+	mockPermissionProvider.On("Middleware", network.ApiPermission{
+		Permissions: []string{string(model.AdminPermission)},
+	}).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+	return mockPermissionProvider
+}
+
+func TestApiKeyController_IssueBadRequest(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider := mockProviders(user)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockPermissionProvider(), new(auth.MockService))
+
+	rr := network.MockTestController(t, "POST", "/auth/apikey/", `{"version":1,"permissions":["ROOT"]}`, c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permissions[0] must be one of GENERAL ADMIN"`)
+}
+
+func TestApiKeyController_IssueSuccess(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider := mockProviders(user)
+
+	createDto := &dto.CreateApiKey{
+		Version:     1,
+		Permissions: []model.Permission{model.GeneralPermission},
+	}
+
+	issued := &dto.IssuedApiKey{Key: "secret", ApiKey: &dto.InfoApiKey{ID: primitive.NewObjectID()}}
+
+	authService := new(auth.MockService)
+	authService.On("IssueApiKey", createDto, user).Return(issued, nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockPermissionProvider(), authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/apikey/", `{"version":1,"permissions":["GENERAL"]}`, c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"key":"secret"`)
+}
+
+func TestApiKeyController_Revoke(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider := mockProviders(user)
+
+	id := primitive.NewObjectID()
+
+	authService := new(auth.MockService)
+	authService.On("RevokeApiKey", id, user).Return(nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockPermissionProvider(), authService)
+
+	rr := network.MockTestController(t, "PUT", "/auth/apikey/revoke/id/"+id.Hex(), "", c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"api key revoked successfully"`)
+}
+
+func TestApiKeyController_RotateNotFound(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider := mockProviders(user)
+
+	id := primitive.NewObjectID()
+
+	authService := new(auth.MockService)
+	authService.On("RotateApiKey", id, mock.Anything, user).Return(nil, network.NewNotFoundError("api key not found", nil))
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockPermissionProvider(), authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/apikey/rotate/id/"+id.Hex(), `{"overlapSec":3600}`, c)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"api key not found"`)
+}
+
+func TestApiKeyController_AdminKeyRequired(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider := mockProviders(user)
+
+	mockPermissionProvider := new(network.MockPermissionProvider)
+	mockPermissionProvider.On("Middleware", network.ApiPermission{
+		Permissions: []string{string(model.AdminPermission)},
+	}).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		network.NewResponseSender().Send(ctx).ForbiddenError("permission denied: api key does not have ADMIN permission", nil)
+	}))
+
+	mockAuthService := new(auth.MockService)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockPermissionProvider, mockAuthService)
+
+	rr := network.MockTestController(t, "GET", "/auth/apikey/", "", c)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: api key does not have ADMIN permission"`)
+	mockAuthService.AssertNotCalled(t, "GetPaginatedApiKeys", mock.Anything)
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/logger"
+	"github.com/unusualcodeorg/goserve/arch/mongo"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongod "go.mongodb.org/mongo-driver/mongo"
+)
+
+func newApiKeyService() (*service, *mongo.MockQuery[model.ApiKey], *redis.MockCache[model.ApiKey]) {
+	query := new(mongo.MockQuery[model.ApiKey])
+	builder := new(mongo.MockQueryBuilder[model.ApiKey])
+	builder.On("SingleQuery").Return(query)
+	cache := new(redis.MockCache[model.ApiKey])
+
+	s := &service{
+		logger:             logger.NewDiscardLogger(),
+		apikeyQueryBuilder: builder,
+		apikeyCache:        cache,
+	}
+	return s, query, cache
+}
+
+func TestRotateApiKey(t *testing.T) {
+	s, query, cache := newApiKeyService()
+
+	admin := &userModel.User{ID: primitive.NewObjectID()}
+	old := &model.ApiKey{ID: primitive.NewObjectID(), Hash: "old-hash", Version: 1, Status: true}
+	newId := primitive.NewObjectID()
+
+	query.On("FindOne", bson.M{"_id": old.ID}, mock.Anything).Return(old, nil)
+	query.On("InsertOne", mock.Anything).Return(&newId, nil)
+	query.On("UpdateOne", bson.M{"_id": old.ID, "rotatedTo": bson.M{"$exists": false}}, mock.Anything).
+		Return(&mongod.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil)
+	cache.On("Delete", []string{apikeyCacheKey(old.Hash)}).Return(nil)
+
+	issued, err := s.RotateApiKey(old.ID, &dto.RotateApiKey{}, admin)
+	assert.NoError(t, err)
+	assert.Equal(t, newId, issued.ApiKey.ID)
+	query.AssertNotCalled(t, "DeleteOne", mock.Anything)
+	cache.AssertExpectations(t)
+}
+
+func TestRotateApiKey_LostRace(t *testing.T) {
+	s, query, cache := newApiKeyService()
+
+	admin := &userModel.User{ID: primitive.NewObjectID()}
+	old := &model.ApiKey{ID: primitive.NewObjectID(), Hash: "old-hash", Version: 1, Status: true}
+	newId := primitive.NewObjectID()
+
+	// the check passes, an other rotation links the old key before this one
+	query.On("FindOne", bson.M{"_id": old.ID}, mock.Anything).Return(old, nil)
+	query.On("InsertOne", mock.Anything).Return(&newId, nil)
+	query.On("UpdateOne", bson.M{"_id": old.ID, "rotatedTo": bson.M{"$exists": false}}, mock.Anything).
+		Return(&mongod.UpdateResult{MatchedCount: 0, ModifiedCount: 0}, nil)
+	query.On("DeleteOne", bson.M{"_id": newId}).Return(&mongod.DeleteResult{DeletedCount: 1}, nil)
+
+	issued, err := s.RotateApiKey(old.ID, &dto.RotateApiKey{}, admin)
+	assert.Nil(t, issued)
+
+	var apiError network.ApiError
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusBadRequest, apiError.GetCode())
+	assert.Equal(t, "api key is already rotated", apiError.GetMessage())
+	query.AssertExpectations(t)
+	cache.AssertNotCalled(t, "Delete", mock.Anything)
+}
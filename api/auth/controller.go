@@ -3,6 +3,7 @@ package auth
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
 	"github.com/unusualcodeorg/goserve/arch/network"
 	"github.com/unusualcodeorg/goserve/common"
 	"github.com/unusualcodeorg/goserve/utils"
@@ -11,26 +12,40 @@ import (
 type controller struct {
 	network.BaseController
 	common.ContextPayload
-	service Service
+	rateLimitProvider network.RateLimitProvider
+	rateLimits        RateLimits
+	service           Service
 }
 
 func NewController(
 	authProvider network.AuthenticationProvider,
 	authorizeProvider network.AuthorizationProvider,
+	rateLimitProvider network.RateLimitProvider,
+	rateLimits RateLimits,
 	service Service,
 ) network.Controller {
 	return &controller{
-		BaseController: network.NewBaseController("/auth", authProvider, authorizeProvider),
-		ContextPayload: common.NewContextPayload(),
-		service:        service,
+		BaseController:    network.NewBaseController("/auth", authProvider, authorizeProvider),
+		ContextPayload:    common.NewContextPayload(),
+		rateLimitProvider: rateLimitProvider,
+		rateLimits:        rateLimits,
+		service:           service,
 	}
 }
 
 func (c *controller) MountRoutes(group *gin.RouterGroup) {
 	group.POST("/signup/basic", c.signUpBasicHandler)
-	group.POST("/signin/basic", c.signInBasicHandler)
+	group.POST("/signin/basic", c.rateLimitProvider.Middleware(c.rateLimits.SignIn), c.signInBasicHandler)
 	group.POST("/token/refresh", c.tokenRefreshHandler)
 	group.DELETE("/signout", c.Authentication(), c.signOutBasic)
+	group.POST("/verify/email", c.verifyEmailHandler)
+	group.POST("/verify/email/resend", c.Authentication(), c.rateLimitProvider.Middleware(c.rateLimits.VerifyEmail), c.resendEmailVerificationHandler)
+	group.POST("/password/forgot", c.rateLimitProvider.Middleware(c.rateLimits.PasswordReset), c.forgotPasswordHandler)
+	group.POST("/password/reset", c.rateLimitProvider.Middleware(c.rateLimits.PasswordReset), c.resetPasswordHandler)
+	group.POST("/unlock", c.rateLimitProvider.Middleware(c.rateLimits.Unlock), c.unlockAccountHandler)
+	group.PUT("/password/change", c.Authentication(), c.rateLimitProvider.Middleware(c.rateLimits.ChangePassword), c.changePasswordHandler)
+	group.GET("/oidc/:provider/authorize", c.oidcAuthorizeHandler)
+	group.POST("/oidc/:provider/signin", c.rateLimitProvider.Middleware(c.rateLimits.SignIn), c.oidcSignInHandler)
 }
 
 func (c *controller) signUpBasicHandler(ctx *gin.Context) {
@@ -40,7 +55,7 @@ func (c *controller) signUpBasicHandler(ctx *gin.Context) {
 		return
 	}
 
-	data, err := c.service.SignUpBasic(body)
+	data, err := c.service.SignUpBasic(body, ClientDevice(ctx))
 	if err != nil {
 		c.Send(ctx).MixedError(err)
 		return
@@ -56,12 +71,17 @@ func (c *controller) signInBasicHandler(ctx *gin.Context) {
 		return
 	}
 
-	dto, err := c.service.SignInBasic(body)
+	dto, challenge, err := c.service.SignInBasic(body, ClientDevice(ctx))
 	if err != nil {
 		c.Send(ctx).MixedError(err)
 		return
 	}
 
+	if challenge != nil {
+		c.Send(ctx).SuccessDataResponse("mfa required", challenge)
+		return
+	}
+
 	c.Send(ctx).SuccessDataResponse("success", dto)
 }
 
@@ -95,3 +115,133 @@ func (c *controller) tokenRefreshHandler(ctx *gin.Context) {
 
 	c.Send(ctx).SuccessDataResponse("success", dto)
 }
+
+func (c *controller) verifyEmailHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptyVerifyEmail())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	err = c.service.VerifyEmail(body)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessMsgResponse("email verified")
+}
+
+func (c *controller) resendEmailVerificationHandler(ctx *gin.Context) {
+	user := c.MustGetUser(ctx)
+
+	err := c.service.SendEmailVerification(user)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessMsgResponse("verification email sent")
+}
+
+func (c *controller) forgotPasswordHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptyForgotPassword())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	err = c.service.ForgotPassword(body)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessMsgResponse("reset link is sent if the email is registered")
+}
+
+func (c *controller) resetPasswordHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptyResetPassword())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	err = c.service.ResetPassword(body)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessMsgResponse("password reset")
+}
+
+func (c *controller) unlockAccountHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptyUnlockAccount())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	err = c.service.UnlockAccount(body)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessMsgResponse("account unlocked")
+}
+
+func (c *controller) changePasswordHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptyChangePassword())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	data, err := c.service.ChangePassword(user, body, ClientDevice(ctx))
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("password changed", data)
+}
+
+func (c *controller) oidcAuthorizeHandler(ctx *gin.Context) {
+	data, err := c.service.OIDCAuthorize(ctx.Param("provider"))
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("success", data)
+}
+
+func (c *controller) oidcSignInHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptyOIDCSignIn())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	data, challenge, err := c.service.OIDCSignIn(ctx.Param("provider"), body, ClientDevice(ctx))
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	if challenge != nil {
+		c.Send(ctx).SuccessDataResponse("mfa required", challenge)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("success", data)
+}
+
+// the client ip is read from the forwarded headers only behind the trusted proxies of the router
+func ClientDevice(ctx *gin.Context) *model.Device {
+	return model.NewDevice(ctx.Request.UserAgent(), ctx.ClientIP())
+}
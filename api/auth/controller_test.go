@@ -1,13 +1,22 @@
 package auth
 
 import (
+	"bytes"
+	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
 	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestAuthController_SignupBadRequest(t *testing.T) {
@@ -21,9 +30,14 @@ func TestAuthController_SignupBadRequest(t *testing.T) {
 		ctx.Next()
 	}))
 
+	mockRateLimitProvider := new(network.MockRateLimitProvider)
+	mockRateLimitProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
 	authService := new(MockService)
 
-	c := NewController(mockAuthProvider, mockAuthzProvider, authService)
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
 
 	rr := network.MockTestController(t, "POST", "/auth/signup/basic", "{}", c)
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
@@ -41,6 +55,11 @@ func TestAuthController_SignupSuccess(t *testing.T) {
 		ctx.Next()
 	}))
 
+	mockRateLimitProvider := new(network.MockRateLimitProvider)
+	mockRateLimitProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
 	body := `{"email":"test@abc.com","password":"123456","name":"test name"}`
 
 	singUpDto := &dto.SignUpBasic{
@@ -50,11 +69,293 @@ func TestAuthController_SignupSuccess(t *testing.T) {
 	}
 
 	authService := new(MockService)
-	authService.On("SignUpBasic", singUpDto).Return(&dto.UserAuth{}, nil)
+	authService.On("SignUpBasic", singUpDto, mock.AnythingOfType("*model.Device")).Return(&dto.UserAuth{}, nil)
 
-	c := NewController(mockAuthProvider, mockAuthzProvider, authService)
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
 
 	rr := network.MockTestController(t, "POST", "/auth/signup/basic", body, c)
 	assert.Equal(t, http.StatusOK, rr.Code)
 	assert.Contains(t, rr.Body.String(), `"message":"success"`)
 }
+
+func mockProviders(user *userModel.User) (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider, *network.MockRateLimitProvider) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, user)
+		ctx.Next()
+	}))
+
+	mockAuthzProvider := new(network.MockAuthorizationProvider)
+
+	mockRateLimitProvider := new(network.MockRateLimitProvider)
+	mockRateLimitProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	return mockAuthProvider, mockAuthzProvider, mockRateLimitProvider
+}
+
+func TestAuthController_VerifyEmailBadRequest(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+	authService := new(MockService)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/verify/email", "{}", c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"token is required"`)
+}
+
+func TestAuthController_VerifyEmailUsed(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+
+	authService := new(MockService)
+	authService.On("VerifyEmail", &dto.VerifyEmail{Token: "token"}).
+		Return(network.NewBadRequestError("verification token already used", nil))
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/verify/email", `{"token":"token"}`, c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"verification token already used"`)
+}
+
+func TestAuthController_VerifyEmailSuccess(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+
+	authService := new(MockService)
+	authService.On("VerifyEmail", &dto.VerifyEmail{Token: "token"}).Return(nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/verify/email", `{"token":"token"}`, c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"email verified"`)
+	authService.AssertExpectations(t)
+}
+
+func TestAuthController_ResendEmailVerification(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user)
+
+	authService := new(MockService)
+	authService.On("SendEmailVerification", user).Return(nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/verify/email/resend", "", c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"verification email sent"`)
+	authService.AssertExpectations(t)
+}
+
+func TestAuthController_ForgotPassword(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+
+	authService := new(MockService)
+	authService.On("ForgotPassword", &dto.ForgotPassword{Email: "test@abc.com"}).Return(nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/password/forgot", `{"email":"test@abc.com"}`, c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"reset link is sent if the email is registered"`)
+	authService.AssertExpectations(t)
+}
+
+func TestAuthController_ResetPasswordBadRequest(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+	authService := new(MockService)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/password/reset", `{"token":"token"}`, c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"password is required"`)
+}
+
+func TestAuthController_ResetPasswordInvalidToken(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+
+	authService := new(MockService)
+	authService.On("ResetPassword", &dto.ResetPassword{Token: "token", Password: "123456"}).
+		Return(network.NewBadRequestError("invalid or expired reset token", nil))
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/password/reset", `{"token":"token","password":"123456"}`, c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"invalid or expired reset token"`)
+}
+
+func TestAuthController_ChangePasswordSame(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user)
+	authService := new(MockService)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "PUT", "/auth/password/change", `{"oldPassword":"123456","newPassword":"123456"}`, c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"newPassword must differ from the old password"`)
+}
+
+func TestAuthController_ChangePasswordSuccess(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user)
+
+	d := &dto.ChangePassword{OldPassword: "123456", NewPassword: "654321"}
+
+	authService := new(MockService)
+	authService.On("ChangePassword", user, d, mock.AnythingOfType("*model.Device")).
+		Return(dto.NewUserTokens("access", "refresh"), nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "PUT", "/auth/password/change", `{"oldPassword":"123456","newPassword":"654321"}`, c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"password changed"`)
+	assert.Contains(t, rr.Body.String(), `"accessToken":"access"`)
+	authService.AssertExpectations(t)
+}
+
+func TestAuthController_OIDCAuthorizeNotFound(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+
+	authService := new(MockService)
+	authService.On("OIDCAuthorize", "unknown").Return(nil, network.NewNotFoundError("oidc provider not found", nil))
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "GET", "/auth/oidc/unknown/authorize", "", c)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"oidc provider not found"`)
+}
+
+func TestAuthController_OIDCAuthorize(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+
+	authService := new(MockService)
+	authService.On("OIDCAuthorize", "google").Return(dto.NewOIDCAuthorization("https://accounts.google.com/o/oauth2/v2/auth?state=s"), nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "GET", "/auth/oidc/google/authorize", "", c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"url":"https://accounts.google.com/o/oauth2/v2/auth?state=s"`)
+}
+
+func TestAuthController_OIDCSignInBadRequest(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+	authService := new(MockService)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/oidc/google/signin", `{"code":"code"}`, c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"state is required"`)
+}
+
+func TestAuthController_OIDCSignInSuccess(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+
+	d := &dto.OIDCSignIn{Code: "code", State: "state"}
+
+	authService := new(MockService)
+	authService.On("OIDCSignIn", "google", d, mock.AnythingOfType("*model.Device")).Return(&dto.UserAuth{}, nil, nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/oidc/google/signin", `{"code":"code","state":"state"}`, c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"success"`)
+	authService.AssertExpectations(t)
+}
+
+func TestAuthController_SignInMFAChallenge(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+
+	d := &dto.SignInBasic{Email: "test@abc.com", Password: "123456"}
+
+	authService := new(MockService)
+	authService.On("SignInBasic", d, mock.AnythingOfType("*model.Device")).Return(nil, dto.NewMFAChallenge("mfa-token"), nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/signin/basic", `{"email":"test@abc.com","password":"123456"}`, c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"mfa required"`)
+	assert.Contains(t, rr.Body.String(), `"mfaToken":"mfa-token"`)
+	assert.NotContains(t, rr.Body.String(), `"tokens"`)
+}
+
+func TestAuthController_SignInLocked(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+
+	d := &dto.SignInBasic{Email: "test@abc.com", Password: "123456"}
+	locked := network.NewTooManyRequestsRetryError("account is temporarily locked, retry after 900 seconds or use the unlock link sent by email", 900*time.Second, nil)
+
+	authService := new(MockService)
+	authService.On("SignInBasic", d, mock.AnythingOfType("*model.Device")).Return(nil, nil, locked)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/signin/basic", `{"email":"test@abc.com","password":"123456"}`, c)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"account is temporarily locked, retry after 900 seconds or use the unlock link sent by email"`)
+	assert.Equal(t, "900", rr.Header().Get(network.RetryAfterHeader))
+}
+
+func TestAuthController_UnlockAccountBadRequest(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+	authService := new(MockService)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/unlock", "{}", c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"token is required"`)
+}
+
+func TestAuthController_UnlockAccountSuccess(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+
+	d := &dto.UnlockAccount{Token: "token"}
+
+	authService := new(MockService)
+	authService.On("UnlockAccount", d).Return(nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/unlock", `{"token":"token"}`, c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"account unlocked"`)
+	authService.AssertExpectations(t)
+}
+
+func TestAuthController_SignInIgnoresForgedForwardedIP(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil)
+
+	d := &dto.SignInBasic{Email: "test@abc.com", Password: "wrong-password"}
+	remoteIP := mock.MatchedBy(func(device *model.Device) bool { return device.IP == "10.0.0.1" })
+
+	authService := new(MockService)
+	authService.On("SignInBasic", d, remoteIP).Return(nil, nil, network.NewUnauthorizedError("invalid email or password", nil))
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, authService)
+
+	// no proxy is trusted, so the lockout of the ip is keyed on the remote address
+	router := network.NewRouter(gin.TestMode, nil, slog.Default())
+	router.LoadControllers([]network.Controller{c})
+
+	req := httptest.NewRequest("POST", "/auth/signin/basic", bytes.NewBufferString(`{"email":"test@abc.com","password":"wrong-password"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	req.RemoteAddr = "10.0.0.1:1234"
+
+	rr := httptest.NewRecorder()
+	router.GetEngine().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	authService.AssertExpectations(t)
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type ChangePassword struct {
+	OldPassword string `json:"oldPassword" binding:"required" validate:"required"`
+	// the length is checked by the password policy
+	NewPassword string `json:"newPassword" binding:"required" validate:"required,nefield=OldPassword"`
+}
+
+func EmptyChangePassword() *ChangePassword {
+	return &ChangePassword{}
+}
+
+func (d *ChangePassword) GetValue() *ChangePassword {
+	return d
+}
+
+func (d *ChangePassword) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		case "nefield":
+			msgs = append(msgs, fmt.Sprintf("%s must differ from the old password", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+)
+
+type CreateApiKey struct {
+	Version int `json:"version" binding:"required" validate:"required,min=1,max=100"`
+	// api versions the key can call, only its own version when empty
+	Versions    []int              `json:"versions,omitempty" validate:"omitempty,max=100,dive,min=1,max=100"`
+	Permissions []model.Permission `json:"permissions" binding:"required" validate:"required,min=1,dive,oneof=GENERAL ADMIN"`
+	Comments    []string           `json:"comments" validate:"omitempty,max=100,dive,max=1000"`
+	ExpiresAt   *time.Time         `json:"expiresAt,omitempty" validate:"omitempty"`
+}
+
+func EmptyCreateApiKey() *CreateApiKey {
+	return &CreateApiKey{}
+}
+
+func (d *CreateApiKey) GetValue() *CreateApiKey {
+	return d
+}
+
+func (d *CreateApiKey) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		case "min":
+			msgs = append(msgs, fmt.Sprintf("%s must be at least %s", err.Field(), err.Param()))
+		case "max":
+			msgs = append(msgs, fmt.Sprintf("%s must be at most %s", err.Field(), err.Param()))
+		case "oneof":
+			msgs = append(msgs, fmt.Sprintf("%s must be one of %s", err.Field(), err.Param()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
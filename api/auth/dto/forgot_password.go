@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type ForgotPassword struct {
+	Email string `json:"email" binding:"required" validate:"required,email"`
+}
+
+func EmptyForgotPassword() *ForgotPassword {
+	return &ForgotPassword{}
+}
+
+func (d *ForgotPassword) GetValue() *ForgotPassword {
+	return d
+}
+
+func (d *ForgotPassword) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		case "email":
+			msgs = append(msgs, fmt.Sprintf("%s is not a valid email", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// the key itself is never listed, it is shared only once when issued
+type InfoApiKey struct {
+	ID          primitive.ObjectID  `json:"_id" binding:"required" validate:"required"`
+	Version     int                 `json:"version" validate:"required"`
+	Versions    []int               `json:"versions,omitempty"`
+	Permissions []model.Permission  `json:"permissions" validate:"required"`
+	Comments    []string            `json:"comments"`
+	Status      bool                `json:"status"`
+	ExpiresAt   *time.Time          `json:"expiresAt,omitempty"`
+	RotatedTo   *primitive.ObjectID `json:"rotatedTo,omitempty"`
+	CreatedBy   *primitive.ObjectID `json:"createdBy,omitempty"`
+	UpdatedBy   *primitive.ObjectID `json:"updatedBy,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt"`
+}
+
+func NewInfoApiKey(apikey *model.ApiKey) (*InfoApiKey, error) {
+	return utils.MapTo[InfoApiKey](apikey)
+}
+
+func EmptyInfoApiKey() *InfoApiKey {
+	return &InfoApiKey{}
+}
+
+func (d *InfoApiKey) GetValue() *InfoApiKey {
+	return d
+}
+
+func (d *InfoApiKey) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// the keys are never listed, the id identifies the whole token family
+type InfoSession struct {
+	ID         primitive.ObjectID `json:"_id" binding:"required" validate:"required"`
+	UserAgent  string             `json:"userAgent"`
+	IP         string             `json:"ip"`
+	Current    bool               `json:"current"`
+	CreatedAt  time.Time          `json:"createdAt" validate:"required"`
+	LastUsedAt *time.Time         `json:"lastUsedAt,omitempty"`
+}
+
+func NewInfoSession(keystore *model.Keystore, current bool) *InfoSession {
+	return &InfoSession{
+		ID:         keystore.SessionID(),
+		UserAgent:  keystore.Device.UserAgent,
+		IP:         keystore.Device.IP,
+		Current:    current,
+		CreatedAt:  keystore.GetSignedInAt(),
+		LastUsedAt: keystore.LastUsedAt,
+	}
+}
+
+func EmptyInfoSession() *InfoSession {
+	return &InfoSession{}
+}
+
+func (d *InfoSession) GetValue() *InfoSession {
+	return d
+}
+
+func (d *InfoSession) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+)
+
+type IssuedApiKey struct {
+	Key    string      `json:"key" binding:"required" validate:"required"`
+	ApiKey *InfoApiKey `json:"apiKey" binding:"required" validate:"required"`
+}
+
+func NewIssuedApiKey(key string, apikey *model.ApiKey) (*IssuedApiKey, error) {
+	info, err := NewInfoApiKey(apikey)
+	if err != nil {
+		return nil, err
+	}
+	return &IssuedApiKey{
+		Key:    key,
+		ApiKey: info,
+	}, nil
+}
+
+func (d *IssuedApiKey) GetValue() *IssuedApiKey {
+	return d
+}
+
+func (d *IssuedApiKey) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
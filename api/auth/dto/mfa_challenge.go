@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// returned by the sign in instead of the tokens when the user has mfa enabled
+type MFAChallenge struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+}
+
+func NewMFAChallenge(token string) *MFAChallenge {
+	return &MFAChallenge{
+		MFAToken: token,
+	}
+}
+
+func (d *MFAChallenge) GetValue() *MFAChallenge {
+	return d
+}
+
+func (d *MFAChallenge) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// a totp code or a recovery code
+type MFACode struct {
+	Code string `json:"code" binding:"required" validate:"required,max=20"`
+}
+
+func EmptyMFACode() *MFACode {
+	return &MFACode{}
+}
+
+func (d *MFACode) GetValue() *MFACode {
+	return d
+}
+
+func (d *MFACode) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		case "max":
+			msgs = append(msgs, fmt.Sprintf("%s must be at most %s characters", err.Field(), err.Param()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// the client opens the url, the provider then redirects back with the code and the state
+type OIDCAuthorization struct {
+	URL string `json:"url" validate:"required,url"`
+}
+
+func NewOIDCAuthorization(url string) *OIDCAuthorization {
+	return &OIDCAuthorization{
+		URL: url,
+	}
+}
+
+func (d *OIDCAuthorization) GetValue() *OIDCAuthorization {
+	return d
+}
+
+func (d *OIDCAuthorization) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// the code and the state of the provider redirect
+type OIDCSignIn struct {
+	Code  string `json:"code" binding:"required" validate:"required,max=2000"`
+	State string `json:"state" binding:"required" validate:"required,max=200"`
+}
+
+func EmptyOIDCSignIn() *OIDCSignIn {
+	return &OIDCSignIn{}
+}
+
+func (d *OIDCSignIn) GetValue() *OIDCSignIn {
+	return d
+}
+
+func (d *OIDCSignIn) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		case "max":
+			msgs = append(msgs, fmt.Sprintf("%s must be at most %s characters", err.Field(), err.Param()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// shown only once, each code signs in once in place of a totp code
+type RecoveryCodes struct {
+	Codes []string `json:"codes" validate:"required"`
+}
+
+func NewRecoveryCodes(codes []string) *RecoveryCodes {
+	return &RecoveryCodes{
+		Codes: codes,
+	}
+}
+
+func (d *RecoveryCodes) GetValue() *RecoveryCodes {
+	return d
+}
+
+func (d *RecoveryCodes) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
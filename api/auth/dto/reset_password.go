@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type ResetPassword struct {
+	Token string `json:"token" binding:"required" validate:"required"`
+	// the length is checked by the password policy
+	Password string `json:"password" binding:"required" validate:"required"`
+}
+
+func EmptyResetPassword() *ResetPassword {
+	return &ResetPassword{}
+}
+
+func (d *ResetPassword) GetValue() *ResetPassword {
+	return d
+}
+
+func (d *ResetPassword) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
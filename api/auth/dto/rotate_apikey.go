@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type RotateApiKey struct {
+	// duration for which the old key keeps working along with the new one
+	OverlapSec int64      `json:"overlapSec" validate:"min=0,max=2592000"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty" validate:"omitempty"`
+}
+
+func EmptyRotateApiKey() *RotateApiKey {
+	return &RotateApiKey{}
+}
+
+func (d *RotateApiKey) GetValue() *RotateApiKey {
+	return d
+}
+
+func (d *RotateApiKey) Overlap() time.Duration {
+	return time.Duration(d.OverlapSec) * time.Second
+}
+
+func (d *RotateApiKey) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "min":
+			msgs = append(msgs, fmt.Sprintf("%s must be at least %s", err.Field(), err.Param()))
+		case "max":
+			msgs = append(msgs, fmt.Sprintf("%s must be at most %s", err.Field(), err.Param()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
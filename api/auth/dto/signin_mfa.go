@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// the second step of the sign in, with the challenge token of the first one
+type SignInMFA struct {
+	MFAToken string `json:"mfaToken" binding:"required" validate:"required,max=2000"`
+	Code     string `json:"code" binding:"required" validate:"required,max=20"`
+}
+
+func EmptySignInMFA() *SignInMFA {
+	return &SignInMFA{}
+}
+
+func (d *SignInMFA) GetValue() *SignInMFA {
+	return d
+}
+
+func (d *SignInMFA) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		case "max":
+			msgs = append(msgs, fmt.Sprintf("%s must be at most %s characters", err.Field(), err.Param()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
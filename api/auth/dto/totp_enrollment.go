@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// the uri is usually shown as a qr code, the secret for the manual entry
+type TOTPEnrollment struct {
+	Secret string `json:"secret" validate:"required"`
+	URI    string `json:"uri" validate:"required"`
+}
+
+func NewTOTPEnrollment(secret string, uri string) *TOTPEnrollment {
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    uri,
+	}
+}
+
+func (d *TOTPEnrollment) GetValue() *TOTPEnrollment {
+	return d
+}
+
+func (d *TOTPEnrollment) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
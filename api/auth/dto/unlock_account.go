@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type UnlockAccount struct {
+	Token string `json:"token" binding:"required" validate:"required"`
+}
+
+func EmptyUnlockAccount() *UnlockAccount {
+	return &UnlockAccount{}
+}
+
+func (d *UnlockAccount) GetValue() *UnlockAccount {
+	return d
+}
+
+func (d *UnlockAccount) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
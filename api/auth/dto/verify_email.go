@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type VerifyEmail struct {
+	Token string `json:"token" binding:"required" validate:"required"`
+}
+
+func EmptyVerifyEmail() *VerifyEmail {
+	return &VerifyEmail{}
+}
+
+func (d *VerifyEmail) GetValue() *VerifyEmail {
+	return d
+}
+
+func (d *VerifyEmail) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
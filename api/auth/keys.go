@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/unusualcodeorg/goserve/utils"
+)
+
+// supported token signing algorithms
+var signingMethods = map[string]jwt.SigningMethod{
+	jwt.SigningMethodRS256.Alg(): jwt.SigningMethodRS256,
+	jwt.SigningMethodES256.Alg(): jwt.SigningMethodES256,
+	jwt.SigningMethodEdDSA.Alg(): jwt.SigningMethodEdDSA,
+}
+
+func signingMethod(alg string) (jwt.SigningMethod, error) {
+	method, ok := signingMethods[alg]
+	if !ok {
+		return nil, fmt.Errorf("token signing alg %q is not supported", alg)
+	}
+	return method, nil
+}
+
+func loadPrivateKey(path string, method jwt.SigningMethod) (crypto.Signer, error) {
+	pem, err := utils.LoadPEMFileInto(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var key crypto.PrivateKey
+	switch method {
+	case jwt.SigningMethodRS256:
+		key, err = jwt.ParseRSAPrivateKeyFromPEM(pem)
+	case jwt.SigningMethodES256:
+		key, err = jwt.ParseECPrivateKeyFromPEM(pem)
+	case jwt.SigningMethodEdDSA:
+		key, err = jwt.ParseEdPrivateKeyFromPEM(pem)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: not a %s private key", path, method.Alg())
+	}
+	return signer, nil
+}
+
+func loadPublicKey(path string, method jwt.SigningMethod) (crypto.PublicKey, error) {
+	pem, err := utils.LoadPEMFileInto(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var key crypto.PublicKey
+	switch method {
+	case jwt.SigningMethodRS256:
+		key, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+	case jwt.SigningMethodES256:
+		key, err = jwt.ParseECPublicKeyFromPEM(pem)
+	case jwt.SigningMethodEdDSA:
+		key, err = jwt.ParseEdPublicKeyFromPEM(pem)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// the configured public key must be the one of the signing key, a mismatch would publish a key that verifies nothing
+func checkKeyPair(signer crypto.Signer, public crypto.PublicKey) error {
+	key, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !key.Equal(public) {
+		return errors.New("token public key does not match the private key")
+	}
+	return nil
+}
+
+// every pem file of the directory is a retired public key
+func loadPublicKeys(dir string, method jwt.SigningMethod) ([]crypto.PublicKey, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]crypto.PublicKey, 0, len(paths))
+	for _, path := range paths {
+		key, err := loadPublicKey(path, method)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
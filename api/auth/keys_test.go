@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/arch/jwk"
+)
+
+func writeKeyPair(t *testing.T, dir string, alg string) {
+	var block *pem.Block
+	var public crypto.PublicKey
+
+	switch alg {
+	case "RS256":
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.NoError(t, err)
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+		public = &key.PublicKey
+	case "ES256":
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NoError(t, err)
+		der, err := x509.MarshalECPrivateKey(key)
+		assert.NoError(t, err)
+		block = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+		public = &key.PublicKey
+	case "EdDSA":
+		pub, key, err := ed25519.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		assert.NoError(t, err)
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+		public = pub
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "private.pem"), pem.EncodeToMemory(block), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "public.pem"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+}
+
+func newTokenService(t *testing.T, alg string) *service {
+	dir := t.TempDir()
+	writeKeyPair(t, dir, alg)
+
+	method, err := signingMethod(alg)
+	assert.NoError(t, err)
+
+	private, err := loadPrivateKey(filepath.Join(dir, "private.pem"), method)
+	assert.NoError(t, err)
+
+	public, err := loadPublicKey(filepath.Join(dir, "public.pem"), method)
+	assert.NoError(t, err)
+
+	ring, err := jwk.NewKeyRing(method.Alg(), private, public)
+	assert.NoError(t, err)
+
+	return &service{signingMethod: method, keyRing: ring}
+}
+
+func TestSigningMethod_Unsupported(t *testing.T) {
+	_, err := signingMethod("HS256")
+	assert.Error(t, err)
+}
+
+func TestLoadPrivateKey_WrongType(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyPair(t, dir, "RS256")
+
+	_, err := loadPrivateKey(filepath.Join(dir, "private.pem"), jwt.SigningMethodEdDSA)
+	assert.Error(t, err)
+}
+
+func TestService_SignAndVerifyToken(t *testing.T) {
+	for _, alg := range []string{"RS256", "ES256", "EdDSA"} {
+		s := newTokenService(t, alg)
+
+		signed, err := s.SignToken(&model.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "subject"}})
+		assert.NoError(t, err, alg)
+
+		claims, err := s.VerifyToken(signed)
+		assert.NoError(t, err, alg)
+		assert.Equal(t, "subject", claims.Subject, alg)
+	}
+}
+
+func TestService_VerifyToken_AlgConfusion(t *testing.T) {
+	s := newTokenService(t, "RS256")
+	kid, _ := s.keyRing.SigningKey()
+	public, err := s.keyRing.VerificationKey(kid)
+	assert.NoError(t, err)
+
+	// the public key used as the hmac secret
+	der, err := x509.MarshalPKIXPublicKey(public)
+	assert.NoError(t, err)
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "subject"})
+	hmacToken.Header["kid"] = kid
+	hmacSigned, err := hmacToken.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	assert.NoError(t, err)
+
+	_, err = s.VerifyToken(hmacSigned)
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+	_, err = s.DecodeToken(hmacSigned)
+	assert.Error(t, err)
+
+	noneToken := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.RegisteredClaims{Subject: "subject"})
+	noneSigned, err := noneToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+
+	_, err = s.VerifyToken(noneSigned)
+	assert.Error(t, err)
+
+	// a token of an other alg of the supported ones
+	other := newTokenService(t, "ES256")
+	otherSigned, err := other.SignToken(&model.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "subject"}})
+	assert.NoError(t, err)
+
+	_, err = s.VerifyToken(otherSigned)
+	assert.Error(t, err)
+}
+
+func TestCheckKeyPair(t *testing.T) {
+	for _, alg := range []string{"RS256", "ES256", "EdDSA"} {
+		method, err := signingMethod(alg)
+		assert.NoError(t, err)
+
+		dir := t.TempDir()
+		writeKeyPair(t, dir, alg)
+		private, err := loadPrivateKey(filepath.Join(dir, "private.pem"), method)
+		assert.NoError(t, err)
+		public, err := loadPublicKey(filepath.Join(dir, "public.pem"), method)
+		assert.NoError(t, err)
+
+		other := t.TempDir()
+		writeKeyPair(t, other, alg)
+		otherPublic, err := loadPublicKey(filepath.Join(other, "public.pem"), method)
+		assert.NoError(t, err)
+
+		assert.NoError(t, checkKeyPair(private, public), alg)
+		assert.Error(t, checkKeyPair(private, otherPublic), alg)
+	}
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/lockout"
+	"github.com/unusualcodeorg/goserve/arch/mailer"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/utils"
+)
+
+// same for the unknown emails and the wrong passwords, so that the registered emails are not revealed
+const invalidCredentials = "invalid email or password"
+
+// the unknown emails are counted as well, so the lockout does not reveal the registered ones
+// the sign in is let through when the counters fail, the lockout should not take it down
+func (s *service) checkSignIn(email string, ip string) error {
+	ctx := context.Background()
+
+	status, err := s.signInIPGuard.Check(ctx, signInIPKey(ip))
+	if err != nil {
+		s.logger.Warn("could not check the sign in lockout", "error", err)
+		return nil
+	}
+	if !status.Allowed() {
+		return signInLockedError(status, "too many failed sign ins, retry after %d seconds")
+	}
+
+	status, err = s.signInAccountGuard.Check(ctx, signInAccountKey(email))
+	if err != nil {
+		s.logger.Warn("could not check the sign in lockout", "error", err)
+		return nil
+	}
+	if status.Locked {
+		return signInLockedError(status, "account is temporarily locked, retry after %d seconds or use the unlock link sent by email")
+	}
+	if !status.Allowed() {
+		return signInLockedError(status, "too many failed sign ins, retry after %d seconds")
+	}
+
+	return nil
+}
+
+func (s *service) failSignIn(user *userModel.User, email string, ip string) error {
+	s.countFailedSignIn(user, email, ip)
+	return network.NewUnauthorizedError(invalidCredentials, nil)
+}
+
+// the unlock link is mailed when the account gets locked, the user is nil for the unknown emails
+func (s *service) countFailedSignIn(user *userModel.User, email string, ip string) {
+	ctx := context.Background()
+
+	if _, err := s.signInIPGuard.Fail(ctx, signInIPKey(ip)); err != nil {
+		s.logger.Warn("could not count the failed sign in", "error", err)
+	}
+
+	status, err := s.signInAccountGuard.Fail(ctx, signInAccountKey(email))
+	if err != nil {
+		s.logger.Warn("could not count the failed sign in", "error", err)
+	}
+
+	if status != nil && status.Locked && status.Failures == s.signInLockoutThreshold && user != nil {
+		s.logger.Warn("account is locked after the failed sign ins", "user", user.ID.Hex(), "ip", ip)
+		if err := s.sendAccountUnlock(user); err != nil {
+			s.logger.Error("could not send the account unlock", "user", user.ID.Hex(), "error", err)
+		}
+	}
+}
+
+// only the account counter is reset, the ip keeps its failures
+func (s *service) resetSignInLockout(email string) {
+	err := s.signInAccountGuard.Reset(context.Background(), signInAccountKey(email))
+	if err != nil {
+		s.logger.Warn("could not reset the sign in lockout", "error", err)
+	}
+}
+
+func (s *service) sendAccountUnlock(user *userModel.User) error {
+	id, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	claims := model.NewClaims(jwt.RegisteredClaims{
+		Issuer:    s.tokenIssuer,
+		Subject:   user.ID.Hex(),
+		Audience:  []string{s.tokenAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(s.signInLockoutDuration)),
+		ID:        id,
+	}, nil, "", model.ScopeUnlockAccount)
+	claims.Email = user.Email
+
+	token, err := s.SignToken(claims)
+	if err != nil {
+		return err
+	}
+
+	mail := &mailer.Mail{
+		To:      user.Email,
+		Subject: "Your account is locked",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYour account is locked for %s after too many failed sign ins.\n"+
+				"If it was you, you can unlock it now by opening the link below:\n\n%s%s\n\n"+
+				"If it was not you, please reset your password.\n",
+			user.Name, s.signInLockoutDuration, s.accountUnlockURL, token,
+		),
+	}
+
+	return s.sendMail(mail)
+}
+
+// the tokens are single use, the used ones are kept in the denylist till they expire
+func (s *service) UnlockAccount(d *dto.UnlockAccount) error {
+	claims, err := s.VerifyToken(d.Token)
+	if err != nil {
+		return network.NewBadRequestError("invalid unlock token", err)
+	}
+
+	valid := s.ValidateClaims(claims) && claims.HasScope(model.ScopeUnlockAccount) && claims.Email != ""
+	if !valid {
+		return network.NewBadRequestError("invalid unlock token", nil)
+	}
+
+	claimed, err := s.claimToken(claims)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return network.NewBadRequestError("unlock token already used", nil)
+	}
+
+	return s.signInAccountGuard.Reset(context.Background(), signInAccountKey(claims.Email))
+}
+
+func signInAccountKey(email string) string {
+	return "signin:account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func signInIPKey(ip string) string {
+	return "signin:ip:" + ip
+}
+
+// the format gets the seconds to wait, the sender sets them in the Retry-After header as well
+func signInLockedError(status *lockout.Status, format string) error {
+	seconds := int(math.Ceil(status.RetryAfter.Seconds()))
+	return network.NewTooManyRequestsRetryError(fmt.Sprintf(format, seconds), status.RetryAfter, nil)
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/arch/lockout"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/redis"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newUnlockService(t *testing.T) (*service, *redis.MockCache[time.Time], *dto.UnlockAccount) {
+	s := newTokenService(t, "RS256")
+	s.tokenIssuer = "api.goserve.test"
+	s.tokenAudience = "goserve.test"
+	s.signInAccountGuard = lockout.NewGuard(lockout.NewMemoryCounter(), lockout.Policy{Threshold: 1, Window: time.Hour})
+
+	revokedCache := new(redis.MockCache[time.Time])
+	s.revokedTokenCache = revokedCache
+
+	now := time.Now()
+	claims := model.NewClaims(jwt.RegisteredClaims{
+		Issuer:    s.tokenIssuer,
+		Subject:   primitive.NewObjectID().Hex(),
+		Audience:  []string{s.tokenAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		ID:        "unlock-id",
+	}, nil, "", model.ScopeUnlockAccount)
+	claims.Email = "lock@abc.com"
+
+	token, err := s.SignToken(claims)
+	assert.NoError(t, err)
+
+	return s, revokedCache, &dto.UnlockAccount{Token: token}
+}
+
+func TestUnlockAccount(t *testing.T) {
+	s, revokedCache, d := newUnlockService(t)
+	revokedCache.On("SetJSONNX", revokedTokenCacheKey("unlock-id"), mock.Anything, mock.Anything).Return(true, nil)
+
+	ctx := context.Background()
+	status, _ := s.signInAccountGuard.Fail(ctx, signInAccountKey("lock@abc.com"))
+	assert.True(t, status.Locked)
+
+	err := s.UnlockAccount(d)
+	assert.NoError(t, err)
+
+	status, _ = s.signInAccountGuard.Check(ctx, signInAccountKey("lock@abc.com"))
+	assert.False(t, status.Locked)
+}
+
+func TestUnlockAccount_AlreadyUsed(t *testing.T) {
+	s, revokedCache, d := newUnlockService(t)
+	// an other request has claimed the token first
+	revokedCache.On("SetJSONNX", revokedTokenCacheKey("unlock-id"), mock.Anything, mock.Anything).Return(false, nil)
+
+	ctx := context.Background()
+	s.signInAccountGuard.Fail(ctx, signInAccountKey("lock@abc.com"))
+
+	err := s.UnlockAccount(d)
+
+	var apiError network.ApiError
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusBadRequest, apiError.GetCode())
+	assert.Equal(t, "unlock token already used", apiError.GetMessage())
+
+	status, _ := s.signInAccountGuard.Check(ctx, signInAccountKey("lock@abc.com"))
+	assert.True(t, status.Locked)
+}
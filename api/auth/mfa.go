@@ -0,0 +1,434 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/mongo"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/totp"
+	"github.com/unusualcodeorg/goserve/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongod "go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// periods accepted around the current one
+	totpSkew          = 1
+	recoveryCodeCount = 10
+	// codes tried with a challenge before it is revoked
+	mfaChallengeMaxAttempts = 5
+)
+
+func (s *service) EnrollTOTP(user *userModel.User) (*dto.TOTPEnrollment, error) {
+	// the user of the stateless authentication has no email
+	user, err := s.userService.FindUserById(user.ID)
+	if err != nil {
+		return nil, network.NewNotFoundError("user not found", err)
+	}
+
+	existing, err := s.mfaQueryBuilder.SingleQuery().FindOne(bson.M{"user": user.ID}, nil)
+	if err != nil && !errors.Is(err, mongod.ErrNoDocuments) {
+		return nil, err
+	}
+	if existing != nil && existing.Enabled {
+		return nil, network.NewBadRequestError("mfa already enabled", nil)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := s.sealMFASecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// an unconfirmed enrolment is replaced
+	if existing != nil {
+		filter := bson.M{"_id": existing.ID, "enabled": false}
+		update := bson.M{"$set": bson.M{"secret": sealed, "lastCounter": 0, "updatedAt": time.Now()}}
+		result, err := s.mfaQueryBuilder.SingleQuery().UpdateOne(filter, update)
+		if err != nil {
+			return nil, err
+		}
+		if result.MatchedCount == 0 {
+			return nil, network.NewBadRequestError("mfa already enabled", nil)
+		}
+	} else {
+		doc, err := model.NewMFA(user.ID, sealed)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.mfaQueryBuilder.SingleQuery().InsertOne(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	return dto.NewTOTPEnrollment(secret, totp.URI(s.mfaIssuer, user.Email, secret)), nil
+}
+
+// the current session is marked as signed in with mfa, it has just proved the second factor
+func (s *service) ConfirmTOTP(user *userModel.User, keystore *model.Keystore, d *dto.MFACode) (*dto.RecoveryCodes, error) {
+	mfa, err := s.mfaQueryBuilder.SingleQuery().FindOne(bson.M{"user": user.ID, "enabled": false}, nil)
+	if err != nil {
+		return nil, network.NewNotFoundError("mfa enrolment not found", err)
+	}
+
+	valid, err := s.useTOTP(mfa, d.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, network.NewBadRequestError("invalid code", nil)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	filter := bson.M{"_id": mfa.ID, "enabled": false}
+	update := bson.M{"$set": bson.M{"enabled": true, "recoveryCodes": hashes, "confirmedAt": now, "updatedAt": now}}
+	result, err := s.mfaQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if err != nil {
+		return nil, err
+	}
+	if result.ModifiedCount == 0 {
+		return nil, network.NewBadRequestError("mfa already enabled", nil)
+	}
+
+	filter = bson.M{"client": keystore.Client, "family": keystore.Family}
+	if keystore.Family.IsZero() {
+		filter = bson.M{"_id": keystore.ID}
+	}
+	_, err = s.keystoreQueryBuilder.SingleQuery().UpdateMany(filter, bson.M{"$set": bson.M{"mfa": true}})
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewRecoveryCodes(codes), nil
+}
+
+func (s *service) DisableTOTP(user *userModel.User, d *dto.MFACode) error {
+	if s.isMFARequired(user) {
+		return network.NewForbiddenError("permission denied: mfa is required for the user role", nil)
+	}
+
+	mfa, err := s.findEnabledMFA(user)
+	if err != nil {
+		return err
+	}
+
+	valid, err := s.useMFACode(mfa, d.Code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return network.NewBadRequestError("invalid code", nil)
+	}
+
+	_, err = s.mfaQueryBuilder.SingleQuery().DeleteOne(bson.M{"_id": mfa.ID})
+	return err
+}
+
+// only a totp code is accepted, the old recovery codes are replaced
+func (s *service) RegenerateRecoveryCodes(user *userModel.User, d *dto.MFACode) (*dto.RecoveryCodes, error) {
+	mfa, err := s.findEnabledMFA(user)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := s.useTOTP(mfa, d.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, network.NewBadRequestError("invalid code", nil)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$set": bson.M{"recoveryCodes": hashes, "updatedAt": time.Now()}}
+	_, err = s.mfaQueryBuilder.SingleQuery().UpdateOne(bson.M{"_id": mfa.ID}, update)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewRecoveryCodes(codes), nil
+}
+
+// the challenge token is single use, the used ones are kept in the denylist till they expire
+// the wrong codes are counted by the challenge and by the account lockout of the password sign in
+func (s *service) SignInMFA(d *dto.SignInMFA, device *model.Device) (*dto.UserAuth, error) {
+	claims, err := s.VerifyToken(d.MFAToken)
+	if err != nil {
+		return nil, network.NewUnauthorizedError("permission denied: invalid mfa token", err)
+	}
+
+	valid := s.ValidateClaims(claims) && claims.HasScope(model.ScopeMFA)
+	if !valid {
+		return nil, network.NewUnauthorizedError("permission denied: invalid mfa token", nil)
+	}
+
+	revoked, err := s.IsTokenRevoked(claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, network.NewUnauthorizedError("permission denied: invalid mfa token", nil)
+	}
+
+	userId, _ := mongo.NewObjectID(claims.Subject)
+	user, err := s.userService.FindUserById(userId)
+	if err != nil {
+		return nil, network.NewUnauthorizedError("permission denied: invalid mfa token", err)
+	}
+
+	if err := s.checkSignIn(user.Email, device.IP); err != nil {
+		return nil, err
+	}
+
+	// counted before the code is checked, so that the parallel attempts are bounded as well
+	// it fails closed, an uncounted attempt would lift the cap on the guesses
+	attempts, err := s.mfaAttemptCounter.Add(context.Background(), mfaChallengeKey(claims.ID), s.mfaChallengeValidity)
+	if err != nil {
+		return nil, err
+	}
+	if attempts > mfaChallengeMaxAttempts {
+		if err := s.revokeMFAChallenge(claims); err != nil {
+			return nil, err
+		}
+		return nil, network.NewUnauthorizedError("permission denied: invalid mfa token", nil)
+	}
+
+	mfa, err := s.findEnabledMFA(user)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err = s.useMFACode(mfa, d.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		s.countFailedSignIn(user, user.Email, device.IP)
+		if attempts == mfaChallengeMaxAttempts {
+			if err := s.revokeMFAChallenge(claims); err != nil {
+				return nil, err
+			}
+		}
+		return nil, network.NewUnauthorizedError("permission denied: invalid code", nil)
+	}
+
+	// claimed at once, so that one challenge can not complete two concurrent sign ins
+	claimed, err := s.claimToken(claims)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, network.NewUnauthorizedError("permission denied: invalid mfa token", nil)
+	}
+	s.resetSignInLockout(user.Email)
+
+	accessToken, refreshToken, err := s.generateToken(user, device, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := dto.NewUserTokens(accessToken, refreshToken)
+	return dto.NewUserAuth(user, tokens), nil
+}
+
+func (s *service) revokeMFAChallenge(claims *model.Claims) error {
+	now := time.Now()
+	return s.revokedTokenCache.SetJSON(revokedTokenCacheKey(claims.ID), &now, claims.ExpiresAt.Sub(now))
+}
+
+func (s *service) isMFAEnabled(userId primitive.ObjectID) (bool, error) {
+	_, err := s.mfaQueryBuilder.SingleQuery().FindOne(bson.M{"user": userId, "enabled": true}, nil)
+	if errors.Is(err, mongod.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *service) isMFARequired(user *userModel.User) bool {
+	for _, role := range user.RoleDocs {
+		if slices.Contains(s.mfaRequiredRoles, role.Code) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *service) findEnabledMFA(user *userModel.User) (*model.MFA, error) {
+	mfa, err := s.mfaQueryBuilder.SingleQuery().FindOne(bson.M{"user": user.ID, "enabled": true}, nil)
+	if err != nil {
+		return nil, network.NewBadRequestError("mfa not enabled", err)
+	}
+	return mfa, nil
+}
+
+func (s *service) createMFAChallenge(user *userModel.User) (*dto.MFAChallenge, error) {
+	id, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	claims := model.NewClaims(jwt.RegisteredClaims{
+		Issuer:    s.tokenIssuer,
+		Subject:   user.ID.Hex(),
+		Audience:  []string{s.tokenAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(s.mfaChallengeValidity)),
+		ID:        id,
+	}, nil, "", model.ScopeMFA)
+
+	token, err := s.SignToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewMFAChallenge(token), nil
+}
+
+// a six digit code is a totp code, anything else a recovery code
+func (s *service) useMFACode(mfa *model.MFA, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits && strings.Trim(code, "0123456789") == "" {
+		return s.useTOTP(mfa, code)
+	}
+	return s.useRecoveryCode(mfa, code)
+}
+
+// a code can be used only once, the counter of the last used one is kept
+func (s *service) useTOTP(mfa *model.MFA, code string) (bool, error) {
+	secret, err := s.openMFASecret(mfa.Secret)
+	if err != nil {
+		return false, err
+	}
+
+	counter, valid := totp.Validate(secret, strings.TrimSpace(code), time.Now(), totpSkew)
+	if !valid {
+		return false, nil
+	}
+
+	filter := bson.M{"_id": mfa.ID, "lastCounter": bson.M{"$lt": counter}}
+	update := bson.M{"$set": bson.M{"lastCounter": counter}}
+	result, err := s.mfaQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (s *service) useRecoveryCode(mfa *model.MFA, code string) (bool, error) {
+	hash := hashRecoveryCode(code)
+	filter := bson.M{"_id": mfa.ID, "recoveryCodes": hash}
+	update := bson.M{"$pull": bson.M{"recoveryCodes": hash}, "$set": bson.M{"updatedAt": time.Now()}}
+	result, err := s.mfaQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func mfaChallengeKey(id string) string {
+	return "signin:mfa:" + id
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		random, err := utils.GenerateRandomString(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes[i] = random[:5] + "-" + random[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// the codes are random, so a fast hash is enough
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func mfaSecretKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+// aes-gcm with the nonce prepended
+func (s *service) sealMFASecret(secret string) (string, error) {
+	gcm, err := s.mfaCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *service) openMFASecret(sealed string) (string, error) {
+	gcm, err := s.mfaCipher()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("mfa secret is malformed")
+	}
+
+	secret, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+func (s *service) mfaCipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.mfaSecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
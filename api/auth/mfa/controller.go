@@ -0,0 +1,138 @@
+package mfa
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/api/auth"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+)
+
+type controller struct {
+	network.BaseController
+	common.ContextPayload
+	rateLimitProvider network.RateLimitProvider
+	service           auth.Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	rateLimitProvider network.RateLimitProvider,
+	service auth.Service,
+) network.Controller {
+	return &controller{
+		BaseController:    network.NewBaseController("/auth/mfa", authProvider, authorizeProvider),
+		ContextPayload:    common.NewContextPayload(),
+		rateLimitProvider: rateLimitProvider,
+		service:           service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	signInLimit := network.RateLimit{
+		Name:     "signin_mfa",
+		Requests: 10,
+		Window:   15 * time.Minute,
+		Key:      network.RateLimitByIP,
+	}
+
+	codeLimit := network.RateLimit{
+		Name:     "mfa_code",
+		Requests: 10,
+		Window:   15 * time.Minute,
+		Key:      common.RateLimitByUser,
+	}
+
+	group.POST("/signin", c.rateLimitProvider.Middleware(signInLimit), c.signInHandler)
+
+	group.POST("/totp", c.Authentication(), c.enrollTOTPHandler)
+	group.POST("/totp/confirm", c.Authentication(), c.rateLimitProvider.Middleware(codeLimit), c.confirmTOTPHandler)
+	group.POST("/totp/disable", c.Authentication(), c.rateLimitProvider.Middleware(codeLimit), c.disableTOTPHandler)
+	group.POST("/recovery-codes", c.Authentication(), c.rateLimitProvider.Middleware(codeLimit), c.regenerateRecoveryCodesHandler)
+}
+
+func (c *controller) signInHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptySignInMFA())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	data, err := c.service.SignInMFA(body, auth.ClientDevice(ctx))
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("success", data)
+}
+
+func (c *controller) enrollTOTPHandler(ctx *gin.Context) {
+	user := c.MustGetUser(ctx)
+
+	data, err := c.service.EnrollTOTP(user)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("confirm the enrolment with a code", data)
+}
+
+func (c *controller) confirmTOTPHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptyMFACode())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+	keystore := c.MustGetKeystore(ctx)
+
+	data, err := c.service.ConfirmTOTP(user, keystore, body)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("mfa enabled", data)
+}
+
+func (c *controller) disableTOTPHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptyMFACode())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	err = c.service.DisableTOTP(user, body)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessMsgResponse("mfa disabled")
+}
+
+func (c *controller) regenerateRecoveryCodesHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptyMFACode())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	data, err := c.service.RegenerateRecoveryCodes(user, body)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("success", data)
+}
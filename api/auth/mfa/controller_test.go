@@ -0,0 +1,130 @@
+package mfa
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/unusualcodeorg/goserve/api/auth"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func mockProviders(user *userModel.User, keystore *model.Keystore) (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider, *network.MockRateLimitProvider) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, user)
+		common.NewContextPayload().SetKeystore(ctx, keystore)
+		ctx.Next()
+	}))
+
+	mockAuthzProvider := new(network.MockAuthorizationProvider)
+
+	mockRateLimitProvider := new(network.MockRateLimitProvider)
+	mockRateLimitProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	return mockAuthProvider, mockAuthzProvider, mockRateLimitProvider
+}
+
+func TestMFAController_EnrollTOTP(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user, &model.Keystore{})
+
+	enrollment := dto.NewTOTPEnrollment("JBSWY3DPEHPK3PXP", "otpauth://totp/goserve:test%40abc.com?secret=JBSWY3DPEHPK3PXP")
+
+	authService := new(auth.MockService)
+	authService.On("EnrollTOTP", user).Return(enrollment, nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/mfa/totp", "", c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"secret":"JBSWY3DPEHPK3PXP"`)
+	assert.Contains(t, rr.Body.String(), `"uri":"otpauth://totp/`)
+}
+
+func TestMFAController_ConfirmTOTPBadRequest(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user, &model.Keystore{})
+	authService := new(auth.MockService)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/mfa/totp/confirm", "{}", c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"code is required"`)
+}
+
+func TestMFAController_ConfirmTOTP(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	keystore := &model.Keystore{ID: primitive.NewObjectID(), Client: user.ID}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user, keystore)
+
+	d := &dto.MFACode{Code: "123456"}
+	codes := dto.NewRecoveryCodes([]string{"abcde-12345"})
+
+	authService := new(auth.MockService)
+	authService.On("ConfirmTOTP", user, keystore, d).Return(codes, nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/mfa/totp/confirm", `{"code":"123456"}`, c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"mfa enabled"`)
+	assert.Contains(t, rr.Body.String(), `"codes":["abcde-12345"]`)
+}
+
+func TestMFAController_DisableTOTPRequired(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user, &model.Keystore{})
+
+	d := &dto.MFACode{Code: "123456"}
+
+	authService := new(auth.MockService)
+	authService.On("DisableTOTP", user, d).Return(network.NewForbiddenError("permission denied: mfa is required for the user role", nil))
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/mfa/totp/disable", `{"code":"123456"}`, c)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: mfa is required for the user role"`)
+}
+
+func TestMFAController_SignInInvalidCode(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil, nil)
+
+	d := &dto.SignInMFA{MFAToken: "mfa-token", Code: "000000"}
+
+	authService := new(auth.MockService)
+	authService.On("SignInMFA", d, mock.AnythingOfType("*model.Device")).Return(nil, network.NewUnauthorizedError("permission denied: invalid code", nil))
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/mfa/signin", `{"mfaToken":"mfa-token","code":"000000"}`, c)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: invalid code"`)
+}
+
+func TestMFAController_SignIn(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(nil, nil)
+
+	d := &dto.SignInMFA{MFAToken: "mfa-token", Code: "abcde-12345"}
+
+	authService := new(auth.MockService)
+	authService.On("SignInMFA", d, mock.AnythingOfType("*model.Device")).Return(&dto.UserAuth{}, nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, authService)
+
+	rr := network.MockTestController(t, "POST", "/auth/mfa/signin", `{"mfaToken":"mfa-token","code":"abcde-12345"}`, c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"success"`)
+	authService.AssertExpectations(t)
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/api/user"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/lockout"
+	"github.com/unusualcodeorg/goserve/arch/logger"
+	"github.com/unusualcodeorg/goserve/arch/mongo"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/redis"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongod "go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestSignInMFA_ChallengeClaimedByOtherSignIn(t *testing.T) {
+	s := newTokenService(t, "RS256")
+	s.tokenIssuer = "api.goserve.test"
+	s.tokenAudience = "goserve.test"
+	s.logger = logger.NewDiscardLogger()
+	s.mfaChallengeValidity = 5 * time.Minute
+	s.mfaAttemptCounter = lockout.NewMemoryCounter()
+	s.signInIPGuard = lockout.NewGuard(lockout.NewMemoryCounter(), lockout.Policy{})
+	s.signInAccountGuard = lockout.NewGuard(lockout.NewMemoryCounter(), lockout.Policy{})
+
+	u := &userModel.User{ID: primitive.NewObjectID(), Email: "mfa@abc.com"}
+	userService := new(user.MockService)
+	userService.On("FindUserById", u.ID).Return(u, nil)
+	s.userService = userService
+
+	mfa := &model.MFA{ID: primitive.NewObjectID(), User: u.ID, Enabled: true}
+	query := new(mongo.MockQuery[model.MFA])
+	query.On("FindOne", mock.Anything, mock.Anything).Return(mfa, nil)
+	query.On("UpdateOne", mock.Anything, mock.Anything).Return(&mongod.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil)
+	builder := new(mongo.MockQueryBuilder[model.MFA])
+	builder.On("SingleQuery").Return(query)
+	s.mfaQueryBuilder = builder
+
+	// the concurrent sign in has claimed the challenge after this one checked the denylist
+	revokedCache := new(redis.MockCache[time.Time])
+	revokedCache.On("GetJSON", revokedTokenCacheKey("challenge-id")).Return(nil, goredis.Nil)
+	revokedCache.On("SetJSONNX", revokedTokenCacheKey("challenge-id"), mock.Anything, mock.Anything).Return(false, nil)
+	s.revokedTokenCache = revokedCache
+
+	now := time.Now()
+	token, err := s.SignToken(model.NewClaims(jwt.RegisteredClaims{
+		Issuer:    s.tokenIssuer,
+		Subject:   u.ID.Hex(),
+		Audience:  []string{s.tokenAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(s.mfaChallengeValidity)),
+		ID:        "challenge-id",
+	}, nil, "", model.ScopeMFA))
+	assert.NoError(t, err)
+
+	auth, err := s.SignInMFA(&dto.SignInMFA{MFAToken: token, Code: "aaaaa-aaaaa"}, &model.Device{IP: "10.0.0.1"})
+	assert.Nil(t, auth)
+
+	var apiError network.ApiError
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusUnauthorized, apiError.GetCode())
+	assert.Equal(t, "permission denied: invalid mfa token", apiError.GetMessage())
+	revokedCache.AssertExpectations(t)
+}
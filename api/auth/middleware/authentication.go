@@ -3,6 +3,7 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/unusualcodeorg/goserve/api/auth"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
 	"github.com/unusualcodeorg/goserve/api/user"
 	"github.com/unusualcodeorg/goserve/arch/mongo"
 	"github.com/unusualcodeorg/goserve/arch/network"
@@ -46,7 +47,8 @@ func (m *authenticationProvider) Middleware() gin.HandlerFunc {
 			return
 		}
 
-		valid := m.authService.ValidateClaims(claims)
+		// the other tokens are signed by the same keys, only the access tokens are accepted
+		valid := m.authService.ValidateClaims(claims) && claims.HasScope(model.ScopeAccess)
 		if !valid {
 			m.Send(ctx).UnauthorizedError("permission denied: invalid claims", nil)
 			return
@@ -70,6 +72,12 @@ func (m *authenticationProvider) Middleware() gin.HandlerFunc {
 			return
 		}
 
+		err = m.authService.TouchKeystore(keystore)
+		if err != nil {
+			m.Send(ctx).MixedError(err)
+			return
+		}
+
 		m.SetUser(ctx, user)
 		m.SetKeystore(ctx, keystore)
 
@@ -82,7 +82,7 @@ func TestAuthenticationProvider_VerifyTokenInvalidClaim(t *testing.T) {
 	mockAuthService.AssertNotCalled(t, "FindUserById", mock.Anything)
 
 	token := "Bearer token"
-	claims := &jwt.RegisteredClaims{}
+	claims := &model.Claims{RegisteredClaims: jwt.RegisteredClaims{}}
 
 	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
 	mockAuthService.On("ValidateClaims", claims).Return(false)
@@ -99,13 +99,34 @@ func TestAuthenticationProvider_VerifyTokenInvalidClaim(t *testing.T) {
 	mockAuthService.AssertExpectations(t)
 }
 
+func TestAuthenticationProvider_RefreshToken(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	mockUserService := new(user.MockService)
+
+	claims := model.NewClaims(jwt.RegisteredClaims{ID: "claimId", Subject: primitive.NewObjectID().Hex()}, nil, primitive.NewObjectID().Hex(), model.ScopeRefresh)
+
+	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
+	mockAuthService.On("ValidateClaims", claims).Return(true)
+
+	rr := network.MockTestAuthenticationProvider(
+		t,
+		NewAuthenticationProvider(mockAuthService, mockUserService),
+		network.MockSuccessMsgHandler("success"),
+		primitive.E{Key: network.AuthorizationHeader, Value: "Bearer token"},
+	)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: invalid claims"`)
+	mockUserService.AssertNotCalled(t, "FindUserById", mock.Anything)
+}
+
 func TestAuthenticationProvider_VerifyTokenInvalidClaimUser(t *testing.T) {
 	mockAuthService := new(auth.MockService)
 	mockUserService := new(user.MockService)
 	mockAuthService.AssertNotCalled(t, "FindUserById", mock.Anything)
 
 	token := "Bearer token"
-	claims := &jwt.RegisteredClaims{}
+	claims := &model.Claims{RegisteredClaims: jwt.RegisteredClaims{}, Scope: model.ScopeAccess}
 
 	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
 	mockAuthService.On("ValidateClaims", claims).Return(true)
@@ -129,7 +150,7 @@ func TestAuthenticationProvider_VerifyTokenInvalidUser(t *testing.T) {
 
 	token := "Bearer token"
 	userId := primitive.NewObjectID()
-	claims := &jwt.RegisteredClaims{Subject: userId.Hex()}
+	claims := &model.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: userId.Hex()}, Scope: model.ScopeAccess}
 
 	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
 	mockAuthService.On("ValidateClaims", claims).Return(true)
@@ -153,7 +174,7 @@ func TestAuthenticationProvider_VerifyTokenInvalidKaystore(t *testing.T) {
 
 	token := "Bearer token"
 	userId := primitive.NewObjectID()
-	claims := &jwt.RegisteredClaims{ID: "claimId", Subject: userId.Hex()}
+	claims := &model.Claims{RegisteredClaims: jwt.RegisteredClaims{ID: "claimId", Subject: userId.Hex()}, Scope: model.ScopeAccess}
 	user := &userModel.User{ID: userId}
 
 	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
@@ -172,6 +193,35 @@ func TestAuthenticationProvider_VerifyTokenInvalidKaystore(t *testing.T) {
 	assert.Contains(t, rr.Body.String(), `"message":"permission denied: invalid access token"`)
 }
 
+func TestAuthenticationProvider_IdleSession(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	mockUserService := new(user.MockService)
+
+	token := "Bearer token"
+	userId := primitive.NewObjectID()
+	claims := &model.Claims{RegisteredClaims: jwt.RegisteredClaims{ID: "claimId", Subject: userId.Hex()}, Scope: model.ScopeAccess}
+	user := &userModel.User{ID: userId}
+	keystore := &model.Keystore{ID: primitive.NewObjectID()}
+
+	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
+	mockAuthService.On("ValidateClaims", claims).Return(true)
+	mockUserService.On("FindUserById", userId).Return(user, nil)
+	mockAuthService.On("FindKeystore", user, claims.ID).Return(keystore, nil)
+	mockAuthService.On("TouchKeystore", keystore).Return(
+		network.NewUnauthorizedError("permission denied: session expired due to inactivity", nil),
+	)
+
+	rr := network.MockTestAuthenticationProvider(
+		t,
+		NewAuthenticationProvider(mockAuthService, mockUserService),
+		network.MockSuccessMsgHandler("success"),
+		primitive.E{Key: network.AuthorizationHeader, Value: token},
+	)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: session expired due to inactivity"`)
+}
+
 func TestAuthenticationProvider_Success(t *testing.T) {
 	mockAuthService := new(auth.MockService)
 	mockUserService := new(user.MockService)
@@ -179,7 +229,7 @@ func TestAuthenticationProvider_Success(t *testing.T) {
 	token := "Bearer token"
 	userId := primitive.NewObjectID()
 	keystoreId := primitive.NewObjectID()
-	claims := &jwt.RegisteredClaims{ID: "claimId", Subject: userId.Hex()}
+	claims := &model.Claims{RegisteredClaims: jwt.RegisteredClaims{ID: "claimId", Subject: userId.Hex()}, Scope: model.ScopeAccess}
 	user := &userModel.User{ID: userId}
 	keystore := &model.Keystore{ID: keystoreId}
 
@@ -187,6 +237,7 @@ func TestAuthenticationProvider_Success(t *testing.T) {
 	mockAuthService.On("ValidateClaims", claims).Return(true)
 	mockUserService.On("FindUserById", userId).Return(user, nil)
 	mockAuthService.On("FindKeystore", user, claims.ID).Return(keystore, nil)
+	mockAuthService.On("TouchKeystore", keystore).Return(nil)
 
 	mockHandler := func(ctx *gin.Context) {
 		assert.Equal(t, common.NewContextPayload().MustGetUser(ctx).ID, userId)
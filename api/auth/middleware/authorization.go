@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"slices"
+
 	"github.com/gin-gonic/gin"
 	"github.com/unusualcodeorg/goserve/api/user/model"
 	"github.com/unusualcodeorg/goserve/arch/network"
@@ -10,12 +12,15 @@ import (
 type authorizationProvider struct {
 	network.ResponseSender
 	common.ContextPayload
+	mfaRequiredRoles []model.RoleCode
 }
 
-func NewAuthorizationProvider() network.AuthorizationProvider {
+// the mfa required roles are granted only to the sessions signed in with mfa
+func NewAuthorizationProvider(mfaRequiredRoles ...model.RoleCode) network.AuthorizationProvider {
 	return &authorizationProvider{
-		ResponseSender: network.NewResponseSender(),
-		ContextPayload: common.NewContextPayload(),
+		ResponseSender:   network.NewResponseSender(),
+		ContextPayload:   common.NewContextPayload(),
+		mfaRequiredRoles: mfaRequiredRoles,
 	}
 }
 
@@ -28,15 +33,27 @@ func (m *authorizationProvider) Middleware(roleNames ...string) gin.HandlerFunc
 
 		user := m.MustGetUser(ctx)
 
+		roleNames, verified := splitVerifiedOption(roleNames)
+		if verified && !user.Verified {
+			m.Send(ctx).ForbiddenError("permission denied: email not verified", nil)
+			return
+		}
+		if verified && len(roleNames) == 0 {
+			ctx.Next()
+			return
+		}
+
 		hasRole := false
+		mfaRequired := false
 		for _, code := range roleNames {
 			for _, role := range user.RoleDocs {
 				if role.Code == model.RoleCode(code) {
 					hasRole = true
+					mfaRequired = slices.Contains(m.mfaRequiredRoles, role.Code)
 					break
 				}
 			}
-			if hasRole {
+			if hasRole && !mfaRequired {
 				break
 			}
 		}
@@ -46,6 +63,27 @@ func (m *authorizationProvider) Middleware(roleNames ...string) gin.HandlerFunc
 			return
 		}
 
+		if mfaRequired {
+			keystore := m.GetKeystore(ctx)
+			if keystore == nil || !keystore.MFA {
+				m.Send(ctx).ForbiddenError("permission denied: mfa required", nil)
+				return
+			}
+		}
+
 		ctx.Next()
 	}
 }
+
+func splitVerifiedOption(roleNames []string) ([]string, bool) {
+	roles := make([]string, 0, len(roleNames))
+	verified := false
+	for _, name := range roleNames {
+		if name == model.VerifiedOption {
+			verified = true
+			continue
+		}
+		roles = append(roles, name)
+	}
+	return roles, verified
+}
@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	authModel "github.com/unusualcodeorg/goserve/api/auth/model"
 	userModel "github.com/unusualcodeorg/goserve/api/user/model"
 	"github.com/unusualcodeorg/goserve/arch/network"
 	"github.com/unusualcodeorg/goserve/common"
@@ -70,3 +72,145 @@ func TestAuthorizationProvider_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code)
 	assert.Contains(t, rr.Body.String(), `"message":"success"`)
 }
+
+func TestAuthorizationProvider_NotVerified(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID(), Verified: false}
+
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		payload := common.NewContextPayload()
+		payload.SetUser(ctx, user)
+		ctx.Next()
+	}))
+
+	rr := network.MockTestAuthorizationProvider(t, userModel.VerifiedOption,
+		mockAuthProvider,
+		NewAuthorizationProvider(),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: email not verified"`)
+}
+
+func TestAuthorizationProvider_Verified(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID(), Verified: true}
+
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		payload := common.NewContextPayload()
+		payload.SetUser(ctx, user)
+		ctx.Next()
+	}))
+
+	rr := network.MockTestAuthorizationProvider(t, userModel.VerifiedOption,
+		mockAuthProvider,
+		NewAuthorizationProvider(),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"success"`)
+}
+
+func TestAuthorizationProvider_VerifiedWithRole(t *testing.T) {
+	role := &userModel.Role{ID: primitive.NewObjectID(), Code: "CORRECT_ROLE"}
+	user := &userModel.User{ID: primitive.NewObjectID(), RoleDocs: []*userModel.Role{role}, Verified: true}
+
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		payload := common.NewContextPayload()
+		payload.SetUser(ctx, user)
+		ctx.Next()
+	}))
+
+	gin.SetMode(gin.TestMode)
+	rr := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rr)
+	authz := NewAuthorizationProvider()
+	r.Use(mockAuthProvider.Middleware())
+	r.GET("/wrong", authz.Middleware(userModel.VerifiedOption, "WRONG_ROLE"), network.MockSuccessMsgHandler("success"))
+	r.GET("/correct", authz.Middleware(userModel.VerifiedOption, "CORRECT_ROLE"), network.MockSuccessMsgHandler("success"))
+
+	req, _ := http.NewRequest("GET", "/wrong", nil)
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: does not have suffient role"`)
+
+	rr = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/correct", nil)
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestAuthorizationProvider_MFARequired(t *testing.T) {
+	role := &userModel.Role{ID: primitive.NewObjectID(), Code: userModel.RoleCodeAdmin}
+	user := &userModel.User{ID: primitive.NewObjectID(), RoleDocs: []*userModel.Role{role}}
+	keystore := &authModel.Keystore{ID: primitive.NewObjectID(), MFA: false}
+
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		payload := common.NewContextPayload()
+		payload.SetUser(ctx, user)
+		payload.SetKeystore(ctx, keystore)
+		ctx.Next()
+	}))
+
+	rr := network.MockTestAuthorizationProvider(t, string(userModel.RoleCodeAdmin),
+		mockAuthProvider,
+		NewAuthorizationProvider(userModel.RoleCodeAdmin),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: mfa required"`)
+}
+
+func TestAuthorizationProvider_MFASession(t *testing.T) {
+	role := &userModel.Role{ID: primitive.NewObjectID(), Code: userModel.RoleCodeAdmin}
+	user := &userModel.User{ID: primitive.NewObjectID(), RoleDocs: []*userModel.Role{role}}
+	keystore := &authModel.Keystore{ID: primitive.NewObjectID(), MFA: true}
+
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		payload := common.NewContextPayload()
+		payload.SetUser(ctx, user)
+		payload.SetKeystore(ctx, keystore)
+		ctx.Next()
+	}))
+
+	rr := network.MockTestAuthorizationProvider(t, string(userModel.RoleCodeAdmin),
+		mockAuthProvider,
+		NewAuthorizationProvider(userModel.RoleCodeAdmin),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"success"`)
+}
+
+func TestAuthorizationProvider_MFANotRequiredRole(t *testing.T) {
+	admin := &userModel.Role{ID: primitive.NewObjectID(), Code: userModel.RoleCodeAdmin}
+	learner := &userModel.Role{ID: primitive.NewObjectID(), Code: userModel.RoleCodeLearner}
+	user := &userModel.User{ID: primitive.NewObjectID(), RoleDocs: []*userModel.Role{admin, learner}}
+	keystore := &authModel.Keystore{ID: primitive.NewObjectID(), MFA: false}
+
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		payload := common.NewContextPayload()
+		payload.SetUser(ctx, user)
+		payload.SetKeystore(ctx, keystore)
+		ctx.Next()
+	}))
+
+	gin.SetMode(gin.TestMode)
+	rr := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rr)
+	authz := NewAuthorizationProvider(userModel.RoleCodeAdmin)
+	r.Use(mockAuthProvider.Middleware())
+	r.GET("/any", authz.Middleware(string(userModel.RoleCodeAdmin), string(userModel.RoleCodeLearner)), network.MockSuccessMsgHandler("success"))
+
+	req, _ := http.NewRequest("GET", "/any", nil)
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -46,10 +46,10 @@ func TestKeyProtectionMiddleware_WrongApiKey(t *testing.T) {
 func TestKeyProtectionMiddleware_CorrectApiKey(t *testing.T) {
 	mockAuthService := new(auth.MockService)
 	key := "correct"
-	mockAuthService.On("FindApiKey", key).Return(&model.ApiKey{Key: key}, nil)
+	mockAuthService.On("FindApiKey", key).Return(&model.ApiKey{Prefix: key}, nil)
 
 	mockHandler := func(ctx *gin.Context) {
-		assert.Equal(t, common.NewContextPayload().MustGetApiKey(ctx).Key, key)
+		assert.Equal(t, common.NewContextPayload().MustGetApiKey(ctx).Prefix, key)
 		network.NewResponseSender().Send(ctx).SuccessMsgResponse("success")
 	}
 
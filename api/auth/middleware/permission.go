@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+)
+
+type permissionProvider struct {
+	network.ResponseSender
+	common.ContextPayload
+}
+
+// must be mounted after the key protection
+func NewPermissionProvider() network.PermissionProvider {
+	return &permissionProvider{
+		ResponseSender: network.NewResponseSender(),
+		ContextPayload: common.NewContextPayload(),
+	}
+}
+
+func (m *permissionProvider) Middleware(permission network.ApiPermission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		apikey := m.GetApiKey(ctx)
+		if apikey == nil {
+			m.Send(ctx).ForbiddenError("permission denied: api key missing", nil)
+			return
+		}
+
+		if permission.Version > 0 && !apikey.AllowsVersion(permission.Version) {
+			msg := fmt.Sprintf("permission denied: api key is not allowed on api version %d", permission.Version)
+			m.Send(ctx).ForbiddenError(msg, nil)
+			return
+		}
+
+		for _, p := range permission.Permissions {
+			if !apikey.HasPermission(model.Permission(p)) {
+				m.Send(ctx).ForbiddenError("permission denied: api key does not have "+p+" permission", nil)
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
+
+type keyPermission struct {
+	network.ResponseSender
+	handler gin.HandlerFunc
+}
+
+// applies the permission to all the routes mounted after it
+func NewKeyPermission(permission network.ApiPermission) network.RootMiddleware {
+	return &keyPermission{
+		ResponseSender: network.NewResponseSender(),
+		handler:        NewPermissionProvider().Middleware(permission),
+	}
+}
+
+func (m *keyPermission) Attach(engine *gin.Engine) {
+	engine.Use(m.Handler)
+}
+
+func (m *keyPermission) Handler(ctx *gin.Context) {
+	m.handler(ctx)
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/api/auth"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPermissionProvider_MissingPermission(t *testing.T) {
+	key := "general"
+	mockAuthService := new(auth.MockService)
+	mockAuthService.On("FindApiKey", key).Return(&model.ApiKey{
+		Prefix:      key,
+		Version:     1,
+		Permissions: []model.Permission{model.GeneralPermission},
+	}, nil)
+
+	rr := network.MockTestPermissionProvider(t,
+		network.ApiPermission{Version: 1, Permissions: []string{string(model.AdminPermission)}},
+		NewKeyProtection(mockAuthService),
+		NewPermissionProvider(),
+		network.MockSuccessMsgHandler("success"),
+		primitive.E{Key: network.ApiKeyHeader, Value: key},
+	)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: api key does not have ADMIN permission"`)
+}
+
+func TestPermissionProvider_VersionNotAllowed(t *testing.T) {
+	key := "old"
+	mockAuthService := new(auth.MockService)
+	mockAuthService.On("FindApiKey", key).Return(&model.ApiKey{
+		Prefix:      key,
+		Version:     1,
+		Permissions: []model.Permission{model.GeneralPermission},
+	}, nil)
+
+	rr := network.MockTestPermissionProvider(t,
+		network.ApiPermission{Version: 2, Permissions: []string{string(model.GeneralPermission)}},
+		NewKeyProtection(mockAuthService),
+		NewPermissionProvider(),
+		network.MockSuccessMsgHandler("success"),
+		primitive.E{Key: network.ApiKeyHeader, Value: key},
+	)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: api key is not allowed on api version 2"`)
+}
+
+func TestPermissionProvider_Success(t *testing.T) {
+	key := "admin"
+	mockAuthService := new(auth.MockService)
+	mockAuthService.On("FindApiKey", key).Return(&model.ApiKey{
+		Prefix:      key,
+		Version:     2,
+		Versions:    []int{1, 2},
+		Permissions: []model.Permission{model.GeneralPermission, model.AdminPermission},
+	}, nil)
+
+	rr := network.MockTestPermissionProvider(t,
+		network.ApiPermission{Version: 1, Permissions: []string{string(model.GeneralPermission), string(model.AdminPermission)}},
+		NewKeyProtection(mockAuthService),
+		NewPermissionProvider(),
+		network.MockSuccessMsgHandler("success"),
+		primitive.E{Key: network.ApiKeyHeader, Value: key},
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"success"`)
+}
+
+func TestPermissionProvider_VersionNotInAllowedSet(t *testing.T) {
+	key := "new"
+	mockAuthService := new(auth.MockService)
+	mockAuthService.On("FindApiKey", key).Return(&model.ApiKey{
+		Prefix:      key,
+		Version:     2,
+		Permissions: []model.Permission{model.GeneralPermission},
+	}, nil)
+
+	rr := network.MockTestPermissionProvider(t,
+		network.ApiPermission{Version: 1, Permissions: []string{string(model.GeneralPermission)}},
+		NewKeyProtection(mockAuthService),
+		NewPermissionProvider(),
+		network.MockSuccessMsgHandler("success"),
+		primitive.E{Key: network.ApiKeyHeader, Value: key},
+	)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: api key is not allowed on api version 1"`)
+}
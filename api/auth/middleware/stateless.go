@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/api/auth"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/mongo"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+	"github.com/unusualcodeorg/goserve/utils"
+)
+
+type statelessAuthenticationProvider struct {
+	network.ResponseSender
+	common.ContextPayload
+	authService auth.Service
+}
+
+// trusts the signed claims instead of looking up the user and the keystore,
+// the revoked access tokens are checked against the redis denylist
+// the user in the context only has its id, roles and verified flag, and the keystore its session
+func NewStatelessAuthenticationProvider(authService auth.Service) network.AuthenticationProvider {
+	return &statelessAuthenticationProvider{
+		ResponseSender: network.NewResponseSender(),
+		ContextPayload: common.NewContextPayload(),
+		authService:    authService,
+	}
+}
+
+func (m *statelessAuthenticationProvider) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authHeader := ctx.GetHeader(network.AuthorizationHeader)
+		if len(authHeader) == 0 {
+			m.Send(ctx).UnauthorizedError("permission denied: missing Authorization", nil)
+			return
+		}
+
+		token := utils.ExtractBearerToken(authHeader)
+		if token == "" {
+			m.Send(ctx).UnauthorizedError("permission denied: invalid Authorization", nil)
+			return
+		}
+
+		claims, err := m.authService.VerifyToken(token)
+		if err != nil {
+			m.Send(ctx).UnauthorizedError(err.Error(), err)
+			return
+		}
+
+		valid := m.authService.ValidateClaims(claims) && claims.HasScope(model.ScopeAccess)
+		if !valid {
+			m.Send(ctx).UnauthorizedError("permission denied: invalid claims", nil)
+			return
+		}
+
+		userId, err := mongo.NewObjectID(claims.Subject)
+		if err != nil {
+			m.Send(ctx).UnauthorizedError("permission denied: invalid claims subject", nil)
+			return
+		}
+
+		session, err := mongo.NewObjectID(claims.Session)
+		if err != nil {
+			m.Send(ctx).UnauthorizedError("permission denied: invalid claims session", nil)
+			return
+		}
+
+		revoked, err := m.authService.IsTokenRevoked(claims)
+		if err != nil {
+			m.Send(ctx).InternalServerError("something went wrong", err)
+			return
+		}
+		if revoked {
+			m.Send(ctx).UnauthorizedError("permission denied: invalid access token", nil)
+			return
+		}
+
+		roles := make([]*userModel.Role, len(claims.Roles))
+		for i, code := range claims.Roles {
+			roles[i] = &userModel.Role{Code: userModel.RoleCode(code), Status: true}
+		}
+
+		m.SetUser(ctx, &userModel.User{ID: userId, RoleDocs: roles, Verified: claims.EmailVerified, Status: true})
+		m.SetKeystore(ctx, &model.Keystore{Client: userId, PrimaryKey: claims.ID, Family: session, MFA: claims.HasAMR(model.AMRMFA), Status: true})
+
+		ctx.Next()
+	}
+}
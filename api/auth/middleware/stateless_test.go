@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/api/auth"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStatelessAuthenticationProvider_RefreshToken(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+
+	claims := model.NewClaims(jwt.RegisteredClaims{ID: "claimId", Subject: primitive.NewObjectID().Hex()}, nil, primitive.NewObjectID().Hex(), model.ScopeRefresh)
+
+	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
+	mockAuthService.On("ValidateClaims", claims).Return(true)
+
+	rr := network.MockTestAuthenticationProvider(
+		t,
+		NewStatelessAuthenticationProvider(mockAuthService),
+		network.MockSuccessMsgHandler("success"),
+		primitive.E{Key: network.AuthorizationHeader, Value: "Bearer token"},
+	)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: invalid claims"`)
+}
+
+func TestStatelessAuthenticationProvider_Revoked(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+
+	claims := model.NewClaims(jwt.RegisteredClaims{ID: "claimId", Subject: primitive.NewObjectID().Hex()}, nil, primitive.NewObjectID().Hex(), model.ScopeAccess)
+
+	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
+	mockAuthService.On("ValidateClaims", claims).Return(true)
+	mockAuthService.On("IsTokenRevoked", claims).Return(true, nil)
+
+	rr := network.MockTestAuthenticationProvider(
+		t,
+		NewStatelessAuthenticationProvider(mockAuthService),
+		network.MockSuccessMsgHandler("success"),
+		primitive.E{Key: network.AuthorizationHeader, Value: "Bearer token"},
+	)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: invalid access token"`)
+}
+
+func TestStatelessAuthenticationProvider_Success(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+
+	userId := primitive.NewObjectID()
+	session := primitive.NewObjectID()
+	roles := []string{string(userModel.RoleCodeAdmin)}
+	claims := model.NewClaims(jwt.RegisteredClaims{ID: "claimId", Subject: userId.Hex()}, roles, session.Hex(), model.ScopeAccess)
+
+	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
+	mockAuthService.On("ValidateClaims", claims).Return(true)
+	mockAuthService.On("IsTokenRevoked", claims).Return(false, nil)
+
+	mockHandler := func(ctx *gin.Context) {
+		user := common.NewContextPayload().MustGetUser(ctx)
+		assert.Equal(t, userId, user.ID)
+		assert.Equal(t, userModel.RoleCodeAdmin, user.RoleDocs[0].Code)
+
+		keystore := common.NewContextPayload().MustGetKeystore(ctx)
+		assert.Equal(t, session, keystore.SessionID())
+		assert.Equal(t, "claimId", keystore.PrimaryKey)
+		network.NewResponseSender().Send(ctx).SuccessMsgResponse("success")
+	}
+
+	rr := network.MockTestAuthenticationProvider(
+		t,
+		NewStatelessAuthenticationProvider(mockAuthService),
+		mockHandler,
+		primitive.E{Key: network.AuthorizationHeader, Value: "Bearer token"},
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"success"`)
+}
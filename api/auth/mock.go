@@ -1,31 +1,33 @@
 package auth
 
 import (
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/mock"
 	"github.com/unusualcodeorg/goserve/api/auth/dto"
 	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userDto "github.com/unusualcodeorg/goserve/api/user/dto"
 	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	coredto "github.com/unusualcodeorg/goserve/arch/dto"
+	"github.com/unusualcodeorg/goserve/arch/jwk"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) SignUpBasic(signUpDto *dto.SignUpBasic) (*dto.UserAuth, error) {
-	args := m.Called(signUpDto)
+func (m *MockService) SignUpBasic(signUpDto *dto.SignUpBasic, device *model.Device) (*dto.UserAuth, error) {
+	args := m.Called(signUpDto, device)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*dto.UserAuth), args.Error(1)
 }
 
-func (m *MockService) SignInBasic(signInDto *dto.SignInBasic) (*dto.UserAuth, error) {
-	args := m.Called(signInDto)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*dto.UserAuth), args.Error(1)
+func (m *MockService) SignInBasic(signInDto *dto.SignInBasic, device *model.Device) (*dto.UserAuth, *dto.MFAChallenge, error) {
+	args := m.Called(signInDto, device)
+	auth, _ := args.Get(0).(*dto.UserAuth)
+	challenge, _ := args.Get(1).(*dto.MFAChallenge)
+	return auth, challenge, args.Error(2)
 }
 
 func (m *MockService) RenewToken(tokenRefreshDto *dto.TokenRefresh, accessToken string) (*dto.UserTokens, error) {
@@ -46,13 +48,13 @@ func (m *MockService) IsEmailRegisted(email string) bool {
 	return args.Bool(0)
 }
 
-func (m *MockService) GenerateToken(user *userModel.User) (string, string, error) {
-	args := m.Called(user)
+func (m *MockService) GenerateToken(user *userModel.User, device *model.Device) (string, string, error) {
+	args := m.Called(user, device)
 	return args.String(0), args.String(1), args.Error(2)
 }
 
-func (m *MockService) CreateKeystore(client *userModel.User, primaryKey string, secondaryKey string) (*model.Keystore, error) {
-	args := m.Called(client, primaryKey, secondaryKey)
+func (m *MockService) CreateKeystore(client *userModel.User, primaryKey string, secondaryKey string, device *model.Device, parent *model.Keystore) (*model.Keystore, error) {
+	args := m.Called(client, primaryKey, secondaryKey, device, parent)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -75,28 +77,28 @@ func (m *MockService) FindRefreshKeystore(client *userModel.User, pKey string, s
 	return args.Get(0).(*model.Keystore), args.Error(1)
 }
 
-func (m *MockService) VerifyToken(tokenStr string) (*jwt.RegisteredClaims, error) {
+func (m *MockService) VerifyToken(tokenStr string) (*model.Claims, error) {
 	args := m.Called(tokenStr)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*jwt.RegisteredClaims), args.Error(1)
+	return args.Get(0).(*model.Claims), args.Error(1)
 }
 
-func (m *MockService) DecodeToken(tokenStr string) (*jwt.RegisteredClaims, error) {
+func (m *MockService) DecodeToken(tokenStr string) (*model.Claims, error) {
 	args := m.Called(tokenStr)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*jwt.RegisteredClaims), args.Error(1)
+	return args.Get(0).(*model.Claims), args.Error(1)
 }
 
-func (m *MockService) SignToken(claims jwt.RegisteredClaims) (string, error) {
+func (m *MockService) SignToken(claims *model.Claims) (string, error) {
 	args := m.Called(claims)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockService) ValidateClaims(claims *jwt.RegisteredClaims) bool {
+func (m *MockService) ValidateClaims(claims *model.Claims) bool {
 	args := m.Called(claims)
 	return args.Bool(0)
 }
@@ -121,3 +123,199 @@ func (m *MockService) DeleteApiKey(apikey *model.ApiKey) (bool, error) {
 	args := m.Called(apikey)
 	return args.Bool(0), args.Error(1)
 }
+
+func (m *MockService) IssueApiKey(d *dto.CreateApiKey, admin *userModel.User) (*dto.IssuedApiKey, error) {
+	args := m.Called(d, admin)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.IssuedApiKey), args.Error(1)
+}
+
+func (m *MockService) GetPaginatedApiKeys(p *coredto.Pagination) ([]*dto.InfoApiKey, error) {
+	args := m.Called(p)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.InfoApiKey), args.Error(1)
+}
+
+func (m *MockService) RevokeApiKey(id primitive.ObjectID, admin *userModel.User) error {
+	args := m.Called(id, admin)
+	return args.Error(0)
+}
+
+func (m *MockService) RotateApiKey(id primitive.ObjectID, d *dto.RotateApiKey, admin *userModel.User) (*dto.IssuedApiKey, error) {
+	args := m.Called(id, d, admin)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.IssuedApiKey), args.Error(1)
+}
+
+func (m *MockService) BackfillKeystoreExpiry() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockService) HashPlaintextApiKeys() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockService) FindRotatedKeystore(client *userModel.User, primaryKey string, secondaryKey string) (*model.Keystore, error) {
+	args := m.Called(client, primaryKey, secondaryKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Keystore), args.Error(1)
+}
+
+func (m *MockService) RotateKeystore(keystore *model.Keystore) (bool, error) {
+	args := m.Called(keystore)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockService) RevokeKeystoreFamily(keystore *model.Keystore) error {
+	args := m.Called(keystore)
+	return args.Error(0)
+}
+
+func (m *MockService) GetSessions(client *userModel.User, current *model.Keystore) ([]*dto.InfoSession, error) {
+	args := m.Called(client, current)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.InfoSession), args.Error(1)
+}
+
+func (m *MockService) GetUserSessions(userId primitive.ObjectID) ([]*dto.InfoSession, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.InfoSession), args.Error(1)
+}
+
+func (m *MockService) RevokeSession(client *userModel.User, sessionId primitive.ObjectID) error {
+	args := m.Called(client, sessionId)
+	return args.Error(0)
+}
+
+func (m *MockService) RevokeOtherSessions(current *model.Keystore) error {
+	args := m.Called(current)
+	return args.Error(0)
+}
+
+func (m *MockService) RevokeUserSessions(userId primitive.ObjectID) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
+
+func (m *MockService) TouchKeystore(keystore *model.Keystore) error {
+	args := m.Called(keystore)
+	return args.Error(0)
+}
+
+func (m *MockService) KeySet() *jwk.Set {
+	args := m.Called()
+	return args.Get(0).(*jwk.Set)
+}
+
+func (m *MockService) IsTokenRevoked(claims *model.Claims) (bool, error) {
+	args := m.Called(claims)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockService) SendEmailVerification(user *userModel.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockService) ChangeUserEmail(user *userModel.User, current *model.Keystore, d *userDto.ChangeEmail) (*userDto.InfoPrivateUser, bool, error) {
+	args := m.Called(user, current, d)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*userDto.InfoPrivateUser), args.Bool(1), args.Error(2)
+}
+
+func (m *MockService) VerifyEmail(d *dto.VerifyEmail) error {
+	args := m.Called(d)
+	return args.Error(0)
+}
+
+func (m *MockService) ForgotPassword(d *dto.ForgotPassword) error {
+	args := m.Called(d)
+	return args.Error(0)
+}
+
+func (m *MockService) ResetPassword(d *dto.ResetPassword) error {
+	args := m.Called(d)
+	return args.Error(0)
+}
+
+func (m *MockService) ChangePassword(user *userModel.User, d *dto.ChangePassword, device *model.Device) (*dto.UserTokens, error) {
+	args := m.Called(user, d, device)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserTokens), args.Error(1)
+}
+
+func (m *MockService) OIDCAuthorize(provider string) (*dto.OIDCAuthorization, error) {
+	args := m.Called(provider)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.OIDCAuthorization), args.Error(1)
+}
+
+func (m *MockService) OIDCSignIn(provider string, d *dto.OIDCSignIn, device *model.Device) (*dto.UserAuth, *dto.MFAChallenge, error) {
+	args := m.Called(provider, d, device)
+	auth, _ := args.Get(0).(*dto.UserAuth)
+	challenge, _ := args.Get(1).(*dto.MFAChallenge)
+	return auth, challenge, args.Error(2)
+}
+
+func (m *MockService) EnrollTOTP(user *userModel.User) (*dto.TOTPEnrollment, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MockService) ConfirmTOTP(user *userModel.User, keystore *model.Keystore, d *dto.MFACode) (*dto.RecoveryCodes, error) {
+	args := m.Called(user, keystore, d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RecoveryCodes), args.Error(1)
+}
+
+func (m *MockService) DisableTOTP(user *userModel.User, d *dto.MFACode) error {
+	args := m.Called(user, d)
+	return args.Error(0)
+}
+
+func (m *MockService) RegenerateRecoveryCodes(user *userModel.User, d *dto.MFACode) (*dto.RecoveryCodes, error) {
+	args := m.Called(user, d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RecoveryCodes), args.Error(1)
+}
+
+func (m *MockService) SignInMFA(d *dto.SignInMFA, device *model.Device) (*dto.UserAuth, error) {
+	args := m.Called(d, device)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserAuth), args.Error(1)
+}
+
+func (m *MockService) UnlockAccount(d *dto.UnlockAccount) error {
+	args := m.Called(d)
+	return args.Error(0)
+}
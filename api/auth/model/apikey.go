@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -18,23 +19,33 @@ type Permission string
 
 const (
 	GeneralPermission Permission = "GENERAL"
+	AdminPermission   Permission = "ADMIN"
 )
 
+// the key itself is never stored, only its prefix for the lookup and its keyed hash
 type ApiKey struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty"`
-	Key         string             `bson:"key" validate:"required,max=1024"`
-	Version     int                `bson:"version" validate:"required,min=1,max=100"`
-	Permissions []Permission       `bson:"permissions" validate:"required"`
-	Comments    []string           `bson:"comments" validate:"required,max=1000"`
-	Status      bool               `bson:"status" validate:"-"`
-	CreatedAt   time.Time          `bson:"createdAt" validate:"-"`
-	UpdatedAt   time.Time          `bson:"updatedAt" validate:"-"`
+	ID      primitive.ObjectID `bson:"_id,omitempty"`
+	Prefix  string             `bson:"prefix" validate:"required,max=16"`
+	Hash    string             `bson:"hash" validate:"required,len=64"`
+	Version int                `bson:"version" validate:"required,min=1,max=100"`
+	// api versions the key can call, only its own version when empty
+	Versions    []int               `bson:"versions,omitempty" validate:"omitempty,dive,min=1,max=100"`
+	Permissions []Permission        `bson:"permissions" validate:"required"`
+	Comments    []string            `bson:"comments" validate:"required,max=1000"`
+	Status      bool                `bson:"status" validate:"-"`
+	ExpiresAt   *time.Time          `bson:"expiresAt,omitempty" validate:"-"`
+	RotatedTo   *primitive.ObjectID `bson:"rotatedTo,omitempty" validate:"-"`
+	CreatedBy   *primitive.ObjectID `bson:"createdBy,omitempty" validate:"-"`
+	UpdatedBy   *primitive.ObjectID `bson:"updatedBy,omitempty" validate:"-"`
+	CreatedAt   time.Time           `bson:"createdAt" validate:"-"`
+	UpdatedAt   time.Time           `bson:"updatedAt" validate:"-"`
 }
 
-func NewApiKey(key string, version int, permissions []Permission, comments []string) *ApiKey {
+func NewApiKey(prefix string, hash string, version int, permissions []Permission, comments []string) *ApiKey {
 	currentTime := time.Now()
 	return &ApiKey{
-		Key:         key,
+		Prefix:      prefix,
+		Hash:        hash,
 		Version:     version,
 		Permissions: permissions,
 		Comments:    comments,
@@ -48,6 +59,26 @@ func (apikey *ApiKey) GetValue() *ApiKey {
 	return apikey
 }
 
+func (apikey *ApiKey) IsExpired() bool {
+	return apikey.ExpiresAt != nil && !apikey.ExpiresAt.After(time.Now())
+}
+
+func (apikey *ApiKey) AllowsVersion(version int) bool {
+	if len(apikey.Versions) == 0 {
+		return apikey.Version == version
+	}
+	return slices.Contains(apikey.Versions, version)
+}
+
+func (apikey *ApiKey) HasPermission(permission Permission) bool {
+	for _, p := range apikey.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 func (apikey *ApiKey) Validate() error {
 	validate := validator.New()
 	return validate.Struct(apikey)
@@ -57,13 +88,13 @@ func (*ApiKey) EnsureIndexes(db mongo.Database) {
 	indexes := []mongod.IndexModel{
 		{
 			Keys: bson.D{
-				{Key: "code", Value: 1},
+				{Key: "prefix", Value: 1},
 				{Key: "status", Value: 1},
 			},
 		},
 		{
 			Keys: bson.D{
-				{Key: "key", Value: 1},
+				{Key: "hash", Value: 1},
 			},
 			Options: options.Index().SetUnique(true),
 		},
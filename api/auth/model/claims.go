@@ -0,0 +1,60 @@
+package model
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// the access tokens authenticate the requests
+	ScopeAccess = "access"
+	// the refresh tokens only renew the tokens
+	ScopeRefresh = "refresh"
+	// the email verification tokens only verify the email
+	ScopeVerifyEmail = "verify_email"
+	// the mfa challenge tokens only complete the sign in with a second factor
+	ScopeMFA = "mfa"
+	// the unlock tokens only end the sign in lockout of the account
+	ScopeUnlockAccount = "unlock_account"
+)
+
+// authentication methods of rfc 8176
+const (
+	AMRPassword = "pwd"
+	AMRMFA      = "mfa"
+)
+
+type Claims struct {
+	jwt.RegisteredClaims
+	// codes of the user roles when the token was issued
+	Roles []string `json:"roles,omitempty"`
+	// space separated, rfc 8693
+	Scope string `json:"scope,omitempty"`
+	// the token family of the token
+	Session string `json:"sid,omitempty"`
+	// the email the token was issued for
+	Email string `json:"email,omitempty"`
+	// whether the user email was verified when the token was issued
+	EmailVerified bool `json:"email_verified,omitempty"`
+	// how the session was authenticated
+	AMR []string `json:"amr,omitempty"`
+}
+
+func NewClaims(registered jwt.RegisteredClaims, roles []string, session string, scopes ...string) *Claims {
+	return &Claims{
+		RegisteredClaims: registered,
+		Roles:            roles,
+		Scope:            strings.Join(scopes, " "),
+		Session:          session,
+	}
+}
+
+func (claims *Claims) HasScope(scope string) bool {
+	return slices.Contains(strings.Fields(claims.Scope), scope)
+}
+
+func (claims *Claims) HasAMR(method string) bool {
+	return slices.Contains(claims.AMR, method)
+}
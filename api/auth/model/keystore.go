@@ -9,36 +9,111 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	mongod "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const KeystoreCollectionName = "keystores"
 
 type Keystore struct {
-	ID           primitive.ObjectID `bson:"_id,omitempty"`
-	Client       primitive.ObjectID `bson:"client" validate:"required"`
-	PrimaryKey   string             `bson:"pKey" validate:"required"`
-	SecondaryKey string             `bson:"sKey" validate:"required"`
-	Status       bool               `bson:"status" validate:"-"`
-	CreatedAt    time.Time          `bson:"createdAt" validate:"required"`
-	UpdatedAt    time.Time          `bson:"updatedAt" validate:"required"`
+	ID           primitive.ObjectID  `bson:"_id,omitempty"`
+	Client       primitive.ObjectID  `bson:"client" validate:"required"`
+	PrimaryKey   string              `bson:"pKey" validate:"required"`
+	SecondaryKey string              `bson:"sKey" validate:"required"`
+	Family       primitive.ObjectID  `bson:"family" validate:"required"`
+	Parent       *primitive.ObjectID `bson:"parent,omitempty" validate:"-"`
+	RotatedAt    *time.Time          `bson:"rotatedAt,omitempty" validate:"-"`
+	Device       Device              `bson:"device" validate:"-"`
+	MFA          bool                `bson:"mfa" validate:"-"`
+	SignedInAt   time.Time           `bson:"signedInAt" validate:"required"`
+	LastUsedAt   *time.Time          `bson:"lastUsedAt,omitempty" validate:"-"`
+	ExpiresAt    time.Time           `bson:"expiresAt" validate:"required"`
+	Status       bool                `bson:"status" validate:"-"`
+	CreatedAt    time.Time           `bson:"createdAt" validate:"required"`
+	UpdatedAt    time.Time           `bson:"updatedAt" validate:"required"`
 }
 
-func NewKeystore(clientID primitive.ObjectID, primaryKey string, secondaryKey string) (*Keystore, error) {
+// client metadata captured at the sign in
+type Device struct {
+	UserAgent string `bson:"userAgent"`
+	IP        string `bson:"ip"`
+}
+
+func NewDevice(userAgent string, ip string) *Device {
+	return &Device{
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+}
+
+// parent is nil for a sign in, which starts a new token family,
+// otherwise the family, device, mfa and sign in time are carried over
+func NewKeystore(
+	clientID primitive.ObjectID,
+	primaryKey string,
+	secondaryKey string,
+	expiresAt time.Time,
+	device *Device,
+	parent *Keystore,
+) (*Keystore, error) {
 	now := time.Now()
 	k := Keystore{
 		Client:       clientID,
 		PrimaryKey:   primaryKey,
 		SecondaryKey: secondaryKey,
+		Family:       primitive.NewObjectID(),
+		SignedInAt:   now,
+		LastUsedAt:   &now,
+		ExpiresAt:    expiresAt,
 		Status:       true,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
+	if device != nil {
+		k.Device = *device
+	}
+	if parent != nil {
+		if !parent.Family.IsZero() {
+			k.Family = parent.Family
+		}
+		k.Parent = &parent.ID
+		k.Device = parent.Device
+		k.MFA = parent.MFA
+		k.SignedInAt = parent.GetSignedInAt()
+	}
 	if err := k.Validate(); err != nil {
 		return nil, err
 	}
 	return &k, nil
 }
 
+// a session spans the whole token family, keystores created before
+// the token families are a session on their own
+func (keystore *Keystore) SessionID() primitive.ObjectID {
+	if keystore.Family.IsZero() {
+		return keystore.ID
+	}
+	return keystore.Family
+}
+
+func (keystore *Keystore) GetSignedInAt() time.Time {
+	if keystore.SignedInAt.IsZero() {
+		return keystore.CreatedAt
+	}
+	return keystore.SignedInAt
+}
+
+func (keystore *Keystore) IsIdle(timeout time.Duration, now time.Time) bool {
+	lastUsedAt := keystore.CreatedAt
+	if keystore.LastUsedAt != nil {
+		lastUsedAt = *keystore.LastUsedAt
+	}
+	return now.Sub(lastUsedAt) > timeout
+}
+
+func (keystore *Keystore) IsRotated() bool {
+	return keystore.RotatedAt != nil
+}
+
 func (keystore *Keystore) GetValue() *Keystore {
 	return keystore
 }
@@ -65,6 +140,17 @@ func (*Keystore) EnsureIndexes(db mongo.Database) {
 				{Key: "status", Value: 1},
 			},
 		},
+		{
+			Keys: bson.D{
+				{Key: "family", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "expiresAt", Value: 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
 	}
 	mongo.NewQueryBuilder[Keystore](db, KeystoreCollectionName).Query(context.Background()).CreateIndexes(indexes)
 }
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/unusualcodeorg/goserve/arch/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongod "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const MFACollectionName = "mfas"
+
+// the totp secret is stored encrypted and the recovery codes hashed
+// it is enabled once the user confirms the enrolment with a code
+type MFA struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	User          primitive.ObjectID `bson:"user" validate:"required"`
+	Secret        string             `bson:"secret" validate:"required"`
+	Enabled       bool               `bson:"enabled" validate:"-"`
+	LastCounter   int64              `bson:"lastCounter" validate:"-"`
+	RecoveryCodes []string           `bson:"recoveryCodes" validate:"-"`
+	ConfirmedAt   *time.Time         `bson:"confirmedAt,omitempty" validate:"-"`
+	CreatedAt     time.Time          `bson:"createdAt" validate:"required"`
+	UpdatedAt     time.Time          `bson:"updatedAt" validate:"required"`
+}
+
+func NewMFA(userId primitive.ObjectID, secret string) (*MFA, error) {
+	now := time.Now()
+	m := MFA{
+		User:          userId,
+		Secret:        secret,
+		Enabled:       false,
+		RecoveryCodes: []string{},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (mfa *MFA) GetValue() *MFA {
+	return mfa
+}
+
+func (mfa *MFA) Validate() error {
+	validate := validator.New()
+	return validate.Struct(mfa)
+}
+
+func (*MFA) EnsureIndexes(db mongo.Database) {
+	indexes := []mongod.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "user", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	mongo.NewQueryBuilder[MFA](db, MFACollectionName).Query(context.Background()).CreateIndexes(indexes)
+}
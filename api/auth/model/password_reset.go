@@ -0,0 +1,70 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/unusualcodeorg/goserve/arch/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongod "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const PasswordResetCollectionName = "password_resets"
+
+// the token itself is never stored, only its sha256 hash
+type PasswordReset struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	User      primitive.ObjectID `bson:"user" validate:"required"`
+	Hash      string             `bson:"hash" validate:"required,len=64"`
+	UsedAt    *time.Time         `bson:"usedAt,omitempty" validate:"-"`
+	ExpiresAt time.Time          `bson:"expiresAt" validate:"required"`
+	CreatedAt time.Time          `bson:"createdAt" validate:"required"`
+}
+
+func NewPasswordReset(userId primitive.ObjectID, hash string, expiresAt time.Time) (*PasswordReset, error) {
+	p := PasswordReset{
+		User:      userId,
+		Hash:      hash,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (reset *PasswordReset) GetValue() *PasswordReset {
+	return reset
+}
+
+func (reset *PasswordReset) Validate() error {
+	validate := validator.New()
+	return validate.Struct(reset)
+}
+
+func (*PasswordReset) EnsureIndexes(db mongo.Database) {
+	indexes := []mongod.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "hash", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "user", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "expiresAt", Value: 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	mongo.NewQueryBuilder[PasswordReset](db, PasswordResetCollectionName).Query(context.Background()).CreateIndexes(indexes)
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/oidc"
+	"github.com/unusualcodeorg/goserve/arch/redis"
+	"github.com/unusualcodeorg/goserve/config"
+	"go.mongodb.org/mongo-driver/bson"
+	mongod "go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	oidcStateValidity = 10 * time.Minute
+	oidcTimeout       = 10 * time.Second
+)
+
+// kept in redis between the authorization and the sign in
+type oidcState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"codeVerifier"`
+}
+
+func newOIDCProviders(configs []config.OIDCProvider) map[string]oidc.Provider {
+	client := &http.Client{Timeout: oidcTimeout}
+	providers := make(map[string]oidc.Provider, len(configs))
+	for _, c := range configs {
+		providers[c.Name] = oidc.NewProvider(&oidc.Config{
+			Name:         c.Name,
+			Issuer:       c.Issuer,
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			RedirectURL:  c.RedirectURL,
+			Scopes:       c.Scopes,
+		}, client)
+	}
+	return providers
+}
+
+func (s *service) oidcProvider(name string) (oidc.Provider, error) {
+	provider, ok := s.oidcProviders[name]
+	if !ok {
+		return nil, network.NewNotFoundError("oidc provider not found", nil)
+	}
+	return provider, nil
+}
+
+func (s *service) OIDCAuthorize(name string) (*dto.OIDCAuthorization, error) {
+	provider, err := s.oidcProvider(name)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := oidc.RandomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.RandomToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.RandomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcTimeout)
+	defer cancel()
+
+	url, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return nil, network.NewInternalServerError("oidc provider is not available", err)
+	}
+
+	value := &oidcState{Provider: name, Nonce: nonce, CodeVerifier: verifier}
+	err = s.oidcStateCache.SetJSON(oidcStateCacheKey(state), value, oidcStateValidity)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewOIDCAuthorization(url), nil
+}
+
+// the state is single use, it binds the sign in to the authorization of this server
+func (s *service) OIDCSignIn(name string, d *dto.OIDCSignIn, device *model.Device) (*dto.UserAuth, *dto.MFAChallenge, error) {
+	provider, err := s.oidcProvider(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	state, err := s.oidcStateCache.PopJSON(oidcStateCacheKey(d.State))
+	if redis.IsNil(err) || (err == nil && state.Provider != name) {
+		return nil, nil, network.NewBadRequestError("invalid or expired oidc state", err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcTimeout)
+	defer cancel()
+
+	identity, err := provider.Exchange(ctx, d.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		return nil, nil, network.NewUnauthorizedError("permission denied: oidc sign in failed", err)
+	}
+
+	// an unverified email could belong to someone else
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, nil, network.NewForbiddenError("permission denied: email is not verified by the provider", nil)
+	}
+
+	user, err := s.findOIDCUser(identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.signIn(user, device)
+}
+
+// looks up the linked user, then links the user of the same email, else signs up a new one
+func (s *service) findOIDCUser(identity *oidc.Identity) (*userModel.User, error) {
+	link := userModel.NewIdentity(identity.Provider, identity.Subject)
+
+	user, err := s.userService.FindUserByIdentity(link)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, mongod.ErrNoDocuments) {
+		return nil, err
+	}
+
+	user, err = s.userService.FindUserByEmail(identity.Email)
+	if errors.Is(err, mongod.ErrNoDocuments) {
+		return s.createOIDCUser(identity, link)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// an unverified password could have been set by someone else before the owner of the email,
+	// so it is dropped along with its sessions, the owner can set a new one with the password reset
+	dropPassword := !user.Verified && user.Password != nil
+	if err := s.userService.LinkUserIdentity(user.ID, link, dropPassword); err != nil {
+		return nil, err
+	}
+
+	if dropPassword {
+		s.logger.Warn("security: password of an unverified user dropped on oidc link", "user", user.ID.Hex(), "provider", identity.Provider)
+		if _, err := s.revokeKeystores(bson.M{"client": user.ID}); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Verified = true
+	user.Identities = append(user.Identities, *link)
+	return user, nil
+}
+
+func (s *service) createOIDCUser(identity *oidc.Identity, link *userModel.Identity) (*userModel.User, error) {
+	role, err := s.userService.FindRoleByCode(userModel.RoleCodeLearner)
+	if err != nil {
+		return nil, err
+	}
+
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+	if runes := []rune(name); len(runes) > 200 {
+		name = string(runes[:200])
+	}
+
+	var picture *string
+	if identity.Picture != "" && len(identity.Picture) <= 500 {
+		picture = &identity.Picture
+	}
+
+	user, err := userModel.NewUser(identity.Email, "", name, picture, []*userModel.Role{role})
+	if err != nil {
+		return nil, err
+	}
+	user.Verified = true
+	user.Identities = []userModel.Identity{*link}
+
+	return s.userService.CreateUser(user)
+}
+
+func oidcStateCacheKey(state string) string {
+	return "oidc_state_" + state
+}
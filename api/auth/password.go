@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/mailer"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/password"
+	"github.com/unusualcodeorg/goserve/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// the result does not tell whether the email is registered,
+// so the errors after the lookup are only logged
+func (s *service) ForgotPassword(d *dto.ForgotPassword) error {
+	user, err := s.userService.FindUserByEmail(d.Email)
+	if err != nil {
+		return nil
+	}
+
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return err
+	}
+
+	// only the latest token is valid
+	_, err = s.resetQueryBuilder.SingleQuery().DeleteMany(bson.M{"user": user.ID})
+	if err != nil {
+		s.logger.Error("could not delete the password resets", "user", user.ID.Hex(), "error", err)
+		return nil
+	}
+
+	reset, err := model.NewPasswordReset(user.ID, hashResetToken(token), time.Now().Add(s.passwordResetValidity))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.resetQueryBuilder.SingleQuery().InsertOne(reset)
+	if err != nil {
+		s.logger.Error("could not create the password reset", "user", user.ID.Hex(), "error", err)
+		return nil
+	}
+
+	mail := &mailer.Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYou can set a new password by opening the link below:\n\n%s%s\n\n"+
+				"The link expires in %s and works only once. If you did not ask for it, you can ignore this email.\n",
+			user.Name, s.passwordResetURL, token, s.passwordResetValidity,
+		),
+	}
+
+	if err := s.sendMail(mail); err != nil {
+		s.logger.Error("could not send the password reset", "user", user.ID.Hex(), "error", err)
+	}
+
+	return nil
+}
+
+func (s *service) ResetPassword(d *dto.ResetPassword) error {
+	// checked before the token is used, so that it can be used again with an other password
+	hashed, err := s.hashNewPassword(d.Password)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"hash":      hashResetToken(d.Token),
+		"usedAt":    bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": now},
+	}
+
+	reset, err := s.resetQueryBuilder.SingleQuery().FindOne(filter, nil)
+	if err != nil {
+		return network.NewBadRequestError("invalid or expired reset token", err)
+	}
+
+	// only one of the concurrent resets with the same token can use it
+	filter = bson.M{"_id": reset.ID, "usedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"usedAt": now}}
+	result, err := s.resetQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return network.NewBadRequestError("invalid or expired reset token", nil)
+	}
+
+	err = s.setPassword(reset.User, hashed)
+	if err != nil {
+		return err
+	}
+
+	// the reset proves the email, so it ends the sign in lockout as well
+	user, err := s.userService.FindUserById(reset.User)
+	if err == nil {
+		s.resetSignInLockout(user.Email)
+	}
+	return nil
+}
+
+// the current session is signed out as well, the tokens of a new one are returned
+func (s *service) ChangePassword(user *userModel.User, d *dto.ChangePassword, device *model.Device) (*dto.UserTokens, error) {
+	user, err := s.checkUserPassword(user.ID, d.OldPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	hashed, err := s.hashNewPassword(d.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.setPassword(user.ID, hashed)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := s.GenerateToken(user, device)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewUserTokens(accessToken, refreshToken), nil
+}
+
+// the user is returned with its password, so with its current email as well
+func (s *service) checkUserPassword(userId primitive.ObjectID, plain string) (*userModel.User, error) {
+	user, err := s.userService.FindUserWithPasswordById(userId)
+	if err != nil {
+		return nil, network.NewNotFoundError("user not found", err)
+	}
+
+	if user.Password == nil {
+		return nil, network.NewBadRequestError("user has no password", nil)
+	}
+
+	if !s.verifyPassword(plain, *user.Password) {
+		return nil, network.NewUnauthorizedError("wrong password", nil)
+	}
+
+	return user, nil
+}
+
+// all the sessions and the pending resets of the user are revoked
+func (s *service) setPassword(userId primitive.ObjectID, hashed string) error {
+	updated, err := s.userService.UpdateUserPassword(userId, hashed)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return network.NewNotFoundError("user not found", nil)
+	}
+
+	_, err = s.revokeKeystores(bson.M{"client": userId})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.resetQueryBuilder.SingleQuery().DeleteMany(bson.M{"user": userId, "usedAt": bson.M{"$exists": false}})
+	return err
+}
+
+// the new passwords must pass the policy, its errors are sent to the client
+func (s *service) hashNewPassword(plain string) (string, error) {
+	if err := s.passwordPolicy.Validate(plain); err != nil {
+		return "", network.NewBadRequestError(err.Error(), err)
+	}
+
+	hashed, err := s.passwordHasher.Hash(plain)
+	if errors.Is(err, password.ErrPasswordTooLong) {
+		return "", network.NewBadRequestError("password is too long", err)
+	}
+	return hashed, err
+}
+
+// the hashes that can not be verified are logged and rejected
+func (s *service) verifyPassword(plain string, hash string) bool {
+	valid, err := s.passwordHasher.Verify(plain, hash)
+	if err != nil {
+		s.logger.Error("could not verify the password hash", "error", err)
+		return false
+	}
+	return valid
+}
+
+// the outdated hashes are replaced after the sign in, it does not fail on it
+// the hash is replaced only if the password was not changed meanwhile
+func (s *service) rehashPassword(user *userModel.User, plain string) {
+	if !s.passwordHasher.NeedsRehash(*user.Password) {
+		return
+	}
+
+	hashed, err := s.passwordHasher.Hash(plain)
+	if err != nil {
+		s.logger.Warn("could not rehash the password", "user", user.ID.Hex(), "error", err)
+		return
+	}
+
+	_, err = s.userService.ReplaceUserPassword(user.ID, *user.Password, hashed)
+	if err != nil {
+		s.logger.Warn("could not rehash the password", "user", user.ID.Hex(), "error", err)
+	}
+}
+
+// the tokens are random, so a fast hash is enough
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+	"github.com/unusualcodeorg/goserve/config"
+)
+
+// limits of the public auth routes, a limit without requests is disabled
+type RateLimits struct {
+	SignIn        network.RateLimit
+	VerifyEmail   network.RateLimit
+	PasswordReset network.RateLimit
+	Unlock        network.RateLimit
+	// the old password is checked, so it is limited per user
+	ChangePassword network.RateLimit
+}
+
+func NewRateLimits(env *config.Env) RateLimits {
+	return RateLimits{
+		SignIn: network.RateLimit{
+			Name:     "signin",
+			Requests: env.SignInRateLimitRequests,
+			Window:   time.Duration(env.SignInRateLimitWindowSec) * time.Second,
+			Key:      network.RateLimitByIP,
+		},
+		VerifyEmail: network.RateLimit{
+			Name:     "verify_email",
+			Requests: env.VerifyEmailRateLimitRequests,
+			Window:   time.Duration(env.VerifyEmailRateLimitWindowSec) * time.Second,
+			Key:      common.RateLimitByUser,
+		},
+		PasswordReset: network.RateLimit{
+			Name:     "password_reset",
+			Requests: env.PasswordResetRateLimitRequests,
+			Window:   time.Duration(env.PasswordResetRateLimitWindowSec) * time.Second,
+			Key:      network.RateLimitByIP,
+		},
+		Unlock: network.RateLimit{
+			Name:     "unlock",
+			Requests: env.UnlockRateLimitRequests,
+			Window:   time.Duration(env.UnlockRateLimitWindowSec) * time.Second,
+			Key:      network.RateLimitByIP,
+		},
+		ChangePassword: network.RateLimit{
+			Name:     "change_password",
+			Requests: env.ChangePasswordRateLimitRequests,
+			Window:   time.Duration(env.ChangePasswordRateLimitWindowSec) * time.Second,
+			Key:      common.RateLimitByUser,
+		},
+	}
+}
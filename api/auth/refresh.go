@@ -0,0 +1,27 @@
+package auth
+
+import (
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/network"
+)
+
+// a refresh token presented again after its rotation is treated as stolen,
+// so the whole family is revoked, including the chain issued from it
+func (s *service) detectRefreshTokenReuse(user *userModel.User, primaryKey string, secondaryKey string) error {
+	rotated, err := s.FindRotatedKeystore(user, primaryKey, secondaryKey)
+	if err != nil {
+		return network.NewUnauthorizedError("permission denied: claims ids", nil)
+	}
+
+	s.logger.Warn("security: refresh token reuse detected",
+		"user", user.ID.Hex(),
+		"family", rotated.Family.Hex(),
+		"keystore", rotated.ID.Hex(),
+	)
+
+	if err := s.RevokeKeystoreFamily(rotated); err != nil {
+		return err
+	}
+
+	return network.NewUnauthorizedError("permission denied: refresh token reused", nil)
+}
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/api/user"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/logger"
+	"github.com/unusualcodeorg/goserve/arch/mongo"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongod "go.mongodb.org/mongo-driver/mongo"
+)
+
+type refreshFixture struct {
+	service      *service
+	query        *mongo.MockQuery[model.Keystore]
+	revokedCache *redis.MockCache[time.Time]
+	user         *userModel.User
+	primaryKey   string
+	secondaryKey string
+	dto          *dto.TokenRefresh
+	accessToken  string
+}
+
+func newRefreshFixture(t *testing.T, log *logger.Config) *refreshFixture {
+	s := newTokenService(t, "RS256")
+	s.tokenIssuer = "api.goserve.test"
+	s.tokenAudience = "goserve.test"
+	s.accessTokenValidity = 3600
+	s.logger = logger.NewDiscardLogger()
+	if log != nil {
+		s.logger = logger.NewLogger(log)
+	}
+
+	u := &userModel.User{ID: primitive.NewObjectID()}
+	userService := new(user.MockService)
+	userService.On("FindUserById", u.ID).Return(u, nil)
+	s.userService = userService
+
+	query := new(mongo.MockQuery[model.Keystore])
+	builder := new(mongo.MockQueryBuilder[model.Keystore])
+	builder.On("SingleQuery").Return(query)
+	s.keystoreQueryBuilder = builder
+
+	revokedCache := new(redis.MockCache[time.Time])
+	s.revokedTokenCache = revokedCache
+
+	f := &refreshFixture{
+		service:      s,
+		query:        query,
+		revokedCache: revokedCache,
+		user:         u,
+		primaryKey:   "pkey",
+		secondaryKey: "skey",
+	}
+
+	now := jwt.NewNumericDate(time.Now())
+	claims := func(id string) jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Issuer:    s.tokenIssuer,
+			Subject:   u.ID.Hex(),
+			Audience:  []string{s.tokenAudience},
+			IssuedAt:  now,
+			NotBefore: now,
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			ID:        id,
+		}
+	}
+
+	accessToken, err := s.SignToken(model.NewClaims(claims(f.primaryKey), nil, "session", model.ScopeAccess))
+	assert.NoError(t, err)
+	refreshToken, err := s.SignToken(model.NewClaims(claims(f.secondaryKey), nil, "session", model.ScopeRefresh))
+	assert.NoError(t, err)
+
+	f.accessToken = accessToken
+	f.dto = &dto.TokenRefresh{RefreshToken: refreshToken}
+
+	return f
+}
+
+func (f *refreshFixture) activeFilter() bson.M {
+	return bson.M{"client": f.user.ID, "pKey": f.primaryKey, "sKey": f.secondaryKey, "status": true}
+}
+
+func (f *refreshFixture) rotatedFilter() bson.M {
+	return bson.M{"client": f.user.ID, "pKey": f.primaryKey, "sKey": f.secondaryKey, "rotatedAt": bson.M{"$exists": true}}
+}
+
+func TestRenewToken_ReusedRefreshTokenRevokesFamily(t *testing.T) {
+	var buf bytes.Buffer
+	f := newRefreshFixture(t, &logger.Config{Format: logger.FormatText, Level: "info", Output: &buf})
+	f.query.On("FindOne", f.activeFilter(), mock.Anything).Return(nil, mongod.ErrNoDocuments)
+
+	rotatedAt := time.Now().Add(-time.Minute)
+	family := primitive.NewObjectID()
+	rotated := &model.Keystore{ID: primitive.NewObjectID(), Client: f.user.ID, Family: family, PrimaryKey: f.primaryKey, RotatedAt: &rotatedAt}
+	current := &model.Keystore{ID: primitive.NewObjectID(), Client: f.user.ID, Family: family, PrimaryKey: "current-pkey", Status: true}
+	familyFilter := bson.M{"client": f.user.ID, "family": family}
+
+	f.query.On("FindOne", f.rotatedFilter(), mock.Anything).Return(rotated, nil)
+	f.query.On("FindAll", familyFilter, mock.Anything).Return([]*model.Keystore{rotated, current}, nil)
+	f.query.On("DeleteMany", familyFilter).Return(&mongod.DeleteResult{DeletedCount: 2}, nil)
+	f.revokedCache.On("SetJSON", revokedTokenCacheKey(f.primaryKey), mock.Anything, time.Hour).Return(nil)
+	f.revokedCache.On("SetJSON", revokedTokenCacheKey("current-pkey"), mock.Anything, time.Hour).Return(nil)
+
+	tokens, err := f.service.RenewToken(f.dto, f.accessToken)
+	assert.Nil(t, tokens)
+
+	var apiError network.ApiError
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusUnauthorized, apiError.GetCode())
+	assert.Equal(t, "permission denied: refresh token reused", apiError.GetMessage())
+	assert.Contains(t, buf.String(), "refresh token reuse detected")
+	assert.Contains(t, buf.String(), family.Hex())
+	f.query.AssertExpectations(t)
+	f.revokedCache.AssertExpectations(t)
+}
+
+func TestRenewToken_UnknownKeystore(t *testing.T) {
+	f := newRefreshFixture(t, nil)
+	f.query.On("FindOne", f.activeFilter(), mock.Anything).Return(nil, mongod.ErrNoDocuments)
+
+	f.query.On("FindOne", f.rotatedFilter(), mock.Anything).Return(nil, mongod.ErrNoDocuments)
+
+	_, err := f.service.RenewToken(f.dto, f.accessToken)
+
+	var apiError network.ApiError
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, "permission denied: claims ids", apiError.GetMessage())
+	f.query.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything)
+	f.query.AssertNotCalled(t, "DeleteMany", mock.Anything)
+}
+
+func TestRenewToken_ReuseRevokeFailure(t *testing.T) {
+	f := newRefreshFixture(t, nil)
+	f.query.On("FindOne", f.activeFilter(), mock.Anything).Return(nil, mongod.ErrNoDocuments)
+
+	rotatedAt := time.Now()
+	rotated := &model.Keystore{ID: primitive.NewObjectID(), Client: f.user.ID, Family: primitive.NewObjectID(), PrimaryKey: f.primaryKey, RotatedAt: &rotatedAt}
+	revokeErr := errors.New("db down")
+
+	f.query.On("FindOne", f.rotatedFilter(), mock.Anything).Return(rotated, nil)
+	f.query.On("FindAll", mock.Anything, mock.Anything).Return(nil, revokeErr)
+
+	_, err := f.service.RenewToken(f.dto, f.accessToken)
+	assert.Equal(t, revokeErr, err)
+	f.query.AssertNotCalled(t, "DeleteMany", mock.Anything)
+}
+
+func TestRenewToken_Rotation(t *testing.T) {
+	f := newRefreshFixture(t, nil)
+	f.service.refreshTokenValidity = 7200
+
+	signedInAt := time.Now().Add(-time.Hour)
+	keystore := &model.Keystore{ID: primitive.NewObjectID(), Client: f.user.ID, Family: primitive.NewObjectID(), PrimaryKey: f.primaryKey, SecondaryKey: f.secondaryKey, SignedInAt: signedInAt, Status: true}
+	newId := primitive.NewObjectID()
+
+	f.query.On("FindOne", f.activeFilter(), mock.Anything).Return(keystore, nil)
+	f.revokedCache.On("SetJSON", revokedTokenCacheKey(f.primaryKey), mock.Anything, time.Hour).Return(nil)
+	f.query.On("UpdateOne", bson.M{"_id": keystore.ID, "status": true}, mock.Anything).Return(&mongod.UpdateResult{ModifiedCount: 1}, nil)
+	f.query.On("InsertOne", mock.MatchedBy(func(doc *model.Keystore) bool {
+		return doc.Family == keystore.Family && *doc.Parent == keystore.ID && doc.PrimaryKey != f.primaryKey
+	})).Return(&newId, nil)
+
+	tokens, err := f.service.RenewToken(f.dto, f.accessToken)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, tokens) {
+		t.FailNow()
+	}
+
+	claims, err := f.service.VerifyToken(tokens.AccessToken)
+	assert.NoError(t, err)
+	// the session is the family, it is kept across the rotations
+	assert.Equal(t, keystore.Family.Hex(), claims.Session)
+	assert.True(t, claims.HasScope(model.ScopeAccess))
+	f.query.AssertExpectations(t)
+	f.revokedCache.AssertExpectations(t)
+}
+
+func TestRenewToken_LostRace(t *testing.T) {
+	f := newRefreshFixture(t, nil)
+
+	keystore := &model.Keystore{ID: primitive.NewObjectID(), Client: f.user.ID, Family: primitive.NewObjectID(), PrimaryKey: f.primaryKey, SecondaryKey: f.secondaryKey, Status: true}
+
+	// the other renewal has rotated the keystore since it was found
+	f.query.On("FindOne", f.activeFilter(), mock.Anything).Return(keystore, nil)
+	f.revokedCache.On("SetJSON", revokedTokenCacheKey(f.primaryKey), mock.Anything, time.Hour).Return(nil)
+	f.query.On("UpdateOne", bson.M{"_id": keystore.ID, "status": true}, mock.Anything).Return(&mongod.UpdateResult{ModifiedCount: 0}, nil)
+
+	tokens, err := f.service.RenewToken(f.dto, f.accessToken)
+	assert.Nil(t, tokens)
+
+	var apiError network.ApiError
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusUnauthorized, apiError.GetCode())
+	assert.Equal(t, "permission denied: refresh token already used", apiError.GetMessage())
+	f.query.AssertNotCalled(t, "FindOne", f.rotatedFilter(), mock.Anything)
+	f.query.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything)
+	f.query.AssertNotCalled(t, "DeleteMany", mock.Anything)
+	f.query.AssertNotCalled(t, "InsertOne", mock.Anything)
+}
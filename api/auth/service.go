@@ -1,96 +1,293 @@
 package auth
 
 import (
-	"crypto/rsa"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/unusualcodeorg/goserve/api/auth/dto"
 	"github.com/unusualcodeorg/goserve/api/auth/model"
 	"github.com/unusualcodeorg/goserve/api/user"
+	userDto "github.com/unusualcodeorg/goserve/api/user/dto"
 	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	coredto "github.com/unusualcodeorg/goserve/arch/dto"
+	"github.com/unusualcodeorg/goserve/arch/jwk"
+	"github.com/unusualcodeorg/goserve/arch/lockout"
+	"github.com/unusualcodeorg/goserve/arch/lru"
+	"github.com/unusualcodeorg/goserve/arch/mailer"
 	"github.com/unusualcodeorg/goserve/arch/mongo"
 	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/oidc"
+	"github.com/unusualcodeorg/goserve/arch/password"
+	"github.com/unusualcodeorg/goserve/arch/redis"
 	"github.com/unusualcodeorg/goserve/config"
 	"github.com/unusualcodeorg/goserve/utils"
 	"go.mongodb.org/mongo-driver/bson"
-	"golang.org/x/crypto/bcrypt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongod "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Service interface {
-	SignUpBasic(signUpDto *dto.SignUpBasic) (*dto.UserAuth, error)
-	SignInBasic(signInDto *dto.SignInBasic) (*dto.UserAuth, error)
+	SignUpBasic(signUpDto *dto.SignUpBasic, device *model.Device) (*dto.UserAuth, error)
+	SignInBasic(signInDto *dto.SignInBasic, device *model.Device) (*dto.UserAuth, *dto.MFAChallenge, error)
 	RenewToken(tokenRefreshDto *dto.TokenRefresh, accessToken string) (*dto.UserTokens, error)
 	SignOut(keystore *model.Keystore) error
 	IsEmailRegisted(email string) bool
-	GenerateToken(user *userModel.User) (string, string, error)
-	CreateKeystore(client *userModel.User, primaryKey string, secondaryKey string) (*model.Keystore, error)
+	GenerateToken(user *userModel.User, device *model.Device) (string, string, error)
+	CreateKeystore(client *userModel.User, primaryKey string, secondaryKey string, device *model.Device, parent *model.Keystore) (*model.Keystore, error)
 	FindKeystore(client *userModel.User, primaryKey string) (*model.Keystore, error)
 	FindRefreshKeystore(client *userModel.User, pKey string, sKey string) (*model.Keystore, error)
-	VerifyToken(tokenStr string) (*jwt.RegisteredClaims, error)
-	DecodeToken(tokenStr string) (*jwt.RegisteredClaims, error)
-	SignToken(claims jwt.RegisteredClaims) (string, error)
-	ValidateClaims(claims *jwt.RegisteredClaims) bool
+	FindRotatedKeystore(client *userModel.User, pKey string, sKey string) (*model.Keystore, error)
+	RotateKeystore(keystore *model.Keystore) (bool, error)
+	RevokeKeystoreFamily(keystore *model.Keystore) error
+	TouchKeystore(keystore *model.Keystore) error
+	GetSessions(client *userModel.User, current *model.Keystore) ([]*dto.InfoSession, error)
+	GetUserSessions(userId primitive.ObjectID) ([]*dto.InfoSession, error)
+	RevokeSession(client *userModel.User, sessionId primitive.ObjectID) error
+	RevokeOtherSessions(current *model.Keystore) error
+	RevokeUserSessions(userId primitive.ObjectID) error
+	VerifyToken(tokenStr string) (*model.Claims, error)
+	DecodeToken(tokenStr string) (*model.Claims, error)
+	SignToken(claims *model.Claims) (string, error)
+	ValidateClaims(claims *model.Claims) bool
+	IsTokenRevoked(claims *model.Claims) (bool, error)
 	FindApiKey(key string) (*model.ApiKey, error)
 	CreateApiKey(key string, version int, permissions []model.Permission, comments []string) (*model.ApiKey, error)
 	DeleteApiKey(apikey *model.ApiKey) (bool, error)
+	IssueApiKey(d *dto.CreateApiKey, admin *userModel.User) (*dto.IssuedApiKey, error)
+	GetPaginatedApiKeys(p *coredto.Pagination) ([]*dto.InfoApiKey, error)
+	RevokeApiKey(id primitive.ObjectID, admin *userModel.User) error
+	RotateApiKey(id primitive.ObjectID, d *dto.RotateApiKey, admin *userModel.User) (*dto.IssuedApiKey, error)
+	HashPlaintextApiKeys() (int, error)
+	BackfillKeystoreExpiry() (int64, error)
+	KeySet() *jwk.Set
+	SendEmailVerification(user *userModel.User) error
+	ChangeUserEmail(user *userModel.User, current *model.Keystore, d *userDto.ChangeEmail) (*userDto.InfoPrivateUser, bool, error)
+	VerifyEmail(d *dto.VerifyEmail) error
+	ForgotPassword(d *dto.ForgotPassword) error
+	ResetPassword(d *dto.ResetPassword) error
+	ChangePassword(user *userModel.User, d *dto.ChangePassword, device *model.Device) (*dto.UserTokens, error)
+	OIDCAuthorize(provider string) (*dto.OIDCAuthorization, error)
+	OIDCSignIn(provider string, d *dto.OIDCSignIn, device *model.Device) (*dto.UserAuth, *dto.MFAChallenge, error)
+	EnrollTOTP(user *userModel.User) (*dto.TOTPEnrollment, error)
+	ConfirmTOTP(user *userModel.User, keystore *model.Keystore, d *dto.MFACode) (*dto.RecoveryCodes, error)
+	DisableTOTP(user *userModel.User, d *dto.MFACode) error
+	RegenerateRecoveryCodes(user *userModel.User, d *dto.MFACode) (*dto.RecoveryCodes, error)
+	SignInMFA(d *dto.SignInMFA, device *model.Device) (*dto.UserAuth, error)
+	UnlockAccount(d *dto.UnlockAccount) error
 }
 
 type service struct {
 	network.BaseService
+	logger               *slog.Logger
 	keystoreQueryBuilder mongo.QueryBuilder[model.Keystore]
 	apikeyQueryBuilder   mongo.QueryBuilder[model.ApiKey]
+	resetQueryBuilder    mongo.QueryBuilder[model.PasswordReset]
+	mfaQueryBuilder      mongo.QueryBuilder[model.MFA]
 	userService          user.Service
+	apikeyHashSecret     []byte
+	// api key cache
+	apikeyCache            redis.Cache[model.ApiKey]
+	apikeyLocalCache       lru.Cache[string, *model.ApiKey]
+	apikeyCacheTTL         time.Duration
+	apikeyNegativeCacheTTL time.Duration
+	// access tokens revoked before their expiry
+	revokedTokenCache redis.Cache[time.Time]
 	// token
-	rsaPrivateKey        *rsa.PrivateKey
-	rsaPublicKey         *rsa.PublicKey
+	signingMethod        jwt.SigningMethod
+	keyRing              jwk.KeyRing
 	accessTokenValidity  time.Duration
 	refreshTokenValidity time.Duration
 	tokenIssuer          string
 	tokenAudience        string
+	// session activity
+	sessionIdleTimeout      time.Duration
+	sessionLastUsedThrottle time.Duration
+	// email verification
+	mailer                    mailer.Mailer
+	emailVerificationURL      string
+	emailVerificationValidity time.Duration
+	// password reset
+	passwordResetURL      string
+	passwordResetValidity time.Duration
+	// oidc sign in
+	oidcProviders  map[string]oidc.Provider
+	oidcStateCache redis.Cache[oidcState]
+	// mfa
+	mfaIssuer            string
+	mfaSecretKey         []byte
+	mfaChallengeValidity time.Duration
+	mfaRequiredRoles     []userModel.RoleCode
+	mfaAttemptCounter    lockout.Counter
+	// sign in lockout
+	signInAccountGuard     lockout.Guard
+	signInIPGuard          lockout.Guard
+	signInLockoutThreshold int
+	signInLockoutDuration  time.Duration
+	accountUnlockURL       string
+	// password
+	passwordHasher password.Hasher
+	passwordPolicy password.Policy
+	// verified for the unknown emails, so that they take as long as the wrong passwords
+	dummyPasswordHash string
 }
 
 func NewService(
 	db mongo.Database,
+	store redis.Store,
 	env *config.Env,
+	logger *slog.Logger,
+	mailer mailer.Mailer,
 	userService user.Service,
 ) Service {
-	privatePem, err := utils.LoadPEMFileInto(env.RSAPrivateKeyPath)
+	signingMethod, err := signingMethod(env.TokenSigningAlg)
 	if err != nil {
 		panic(err)
 	}
-	rsaPrivateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePem)
+
+	privateKey, err := loadPrivateKey(env.TokenPrivateKeyPath, signingMethod)
+	if err != nil {
+		panic(err)
+	}
+
+	publicKey, err := loadPublicKey(env.TokenPublicKeyPath, signingMethod)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := checkKeyPair(privateKey, publicKey); err != nil {
+		panic(err)
+	}
+
+	retiredKeys, err := loadPublicKeys(env.TokenRetiredKeysDir, signingMethod)
+	if err != nil {
+		panic(err)
+	}
+
+	keyRing, err := jwk.NewKeyRing(signingMethod.Alg(), privateKey, append(retiredKeys, publicKey)...)
+	if err != nil {
+		panic(err)
+	}
+
+	if env.ApiKeyHashSecret == "" {
+		panic(errors.New("api key hash secret is missing"))
+	}
+
+	if env.MFASecretKey == "" {
+		panic(errors.New("mfa secret key is missing"))
+	}
+
+	passwordHasher, err := password.NewHasher(password.Config{
+		Algorithm:         env.PasswordHashAlgorithm,
+		BcryptCost:        int(env.PasswordBcryptCost),
+		Argon2Memory:      env.PasswordArgon2MemoryKiB,
+		Argon2Iterations:  env.PasswordArgon2Iterations,
+		Argon2Parallelism: env.PasswordArgon2Parallelism,
+	})
 	if err != nil {
 		panic(err)
 	}
 
-	publicPem, err := utils.LoadPEMFileInto(env.RSAPublicKeyPath)
+	passwordPolicy, err := password.NewPolicy(password.PolicyConfig{
+		MinLength:        int(env.PasswordMinLength),
+		MaxLength:        int(env.PasswordMaxLength),
+		BreachedListPath: env.PasswordBreachedListPath,
+	})
 	if err != nil {
 		panic(err)
 	}
 
-	rsaPublicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPem)
+	dummyPassword, err := utils.GenerateRandomString(16)
 	if err != nil {
 		panic(err)
 	}
 
+	dummyPasswordHash, err := passwordHasher.Hash(dummyPassword)
+	if err != nil {
+		panic(err)
+	}
+
+	var apikeyLocalCache lru.Cache[string, *model.ApiKey]
+	if env.ApiKeyLocalCacheSize > 0 {
+		ttl := time.Duration(env.ApiKeyLocalCacheTTLSec) * time.Second
+		apikeyLocalCache = lru.NewCache[string, *model.ApiKey](env.ApiKeyLocalCacheSize, ttl)
+	}
+
+	lockoutCounter := lockout.NewRedisCounter(store)
+	signInLockoutDuration := time.Duration(env.SignInLockoutSec) * time.Second
+
 	return &service{
 		BaseService:          network.NewBaseService(),
+		logger:               logger,
 		userService:          userService,
 		keystoreQueryBuilder: mongo.NewQueryBuilder[model.Keystore](db, model.KeystoreCollectionName),
 		apikeyQueryBuilder:   mongo.NewQueryBuilder[model.ApiKey](db, model.ApiKeyCollectionName),
+		resetQueryBuilder:    mongo.NewQueryBuilder[model.PasswordReset](db, model.PasswordResetCollectionName),
+		mfaQueryBuilder:      mongo.NewQueryBuilder[model.MFA](db, model.MFACollectionName),
+		apikeyHashSecret:     []byte(env.ApiKeyHashSecret),
+		// api key cache
+		apikeyCache:            redis.NewCache[model.ApiKey](store),
+		apikeyLocalCache:       apikeyLocalCache,
+		apikeyCacheTTL:         time.Duration(env.ApiKeyCacheTTLSec) * time.Second,
+		apikeyNegativeCacheTTL: time.Duration(env.ApiKeyNegativeCacheTTLSec) * time.Second,
+		// access tokens revoked before their expiry
+		revokedTokenCache: redis.NewCache[time.Time](store),
 		// token key
-		rsaPrivateKey: rsaPrivateKey,
-		rsaPublicKey:  rsaPublicKey,
+		signingMethod: signingMethod,
+		keyRing:       keyRing,
 		// token claim
 		accessTokenValidity:  time.Duration(env.AccessTokenValiditySec),
 		refreshTokenValidity: time.Duration(env.RefreshTokenValiditySec),
 		tokenIssuer:          env.TokenIssuer,
 		tokenAudience:        env.TokenAudience,
+		// session activity
+		sessionIdleTimeout:      time.Duration(env.SessionIdleTimeoutSec) * time.Second,
+		sessionLastUsedThrottle: time.Duration(env.SessionLastUsedThrottleSec) * time.Second,
+		// email verification
+		mailer:                    mailer,
+		emailVerificationURL:      env.EmailVerificationURL,
+		emailVerificationValidity: time.Duration(env.EmailVerificationValiditySec) * time.Second,
+		// password reset
+		passwordResetURL:      env.PasswordResetURL,
+		passwordResetValidity: time.Duration(env.PasswordResetValiditySec) * time.Second,
+		// oidc sign in
+		oidcProviders:  newOIDCProviders(env.OIDCProviders),
+		oidcStateCache: redis.NewCache[oidcState](store),
+		// mfa
+		mfaIssuer:            env.MFAIssuer,
+		mfaSecretKey:         mfaSecretKey(env.MFASecretKey),
+		mfaChallengeValidity: time.Duration(env.MFAChallengeValiditySec) * time.Second,
+		mfaRequiredRoles:     userModel.ParseRoleCodes(env.MFARequiredRoles),
+		mfaAttemptCounter:    lockoutCounter,
+		// sign in lockout
+		signInAccountGuard: lockout.NewGuard(lockoutCounter, lockout.Policy{
+			FreeAttempts: int(env.SignInFreeAttempts),
+			BaseDelay:    time.Duration(env.SignInDelayBaseSec) * time.Second,
+			MaxDelay:     time.Duration(env.SignInDelayMaxSec) * time.Second,
+			Threshold:    int(env.SignInLockoutThreshold),
+			Window:       signInLockoutDuration,
+		}),
+		signInIPGuard: lockout.NewGuard(lockoutCounter, lockout.Policy{
+			Threshold: int(env.SignInIPLockoutThreshold),
+			Window:    signInLockoutDuration,
+		}),
+		signInLockoutThreshold: int(env.SignInLockoutThreshold),
+		signInLockoutDuration:  signInLockoutDuration,
+		accountUnlockURL:       env.AccountUnlockURL,
+		// password
+		passwordHasher:    passwordHasher,
+		passwordPolicy:    passwordPolicy,
+		dummyPasswordHash: dummyPasswordHash,
 	}
 }
 
-func (s *service) SignUpBasic(signUpDto *dto.SignUpBasic) (*dto.UserAuth, error) {
+func (s *service) SignUpBasic(signUpDto *dto.SignUpBasic, device *model.Device) (*dto.UserAuth, error) {
 	exists := s.IsEmailRegisted(signUpDto.Email)
 	if exists {
 		return nil, network.NewBadRequestError("user already registered", nil)
@@ -103,12 +300,12 @@ func (s *service) SignUpBasic(signUpDto *dto.SignUpBasic) (*dto.UserAuth, error)
 	roles := make([]*userModel.Role, 1)
 	roles[0] = role
 
-	hashed, err := bcrypt.GenerateFromPassword([]byte(signUpDto.Password), 5)
+	hashed, err := s.hashNewPassword(signUpDto.Password)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := userModel.NewUser(signUpDto.Email, string(hashed), signUpDto.Name, signUpDto.ProfilePicUrl, roles)
+	user, err := userModel.NewUser(signUpDto.Email, hashed, signUpDto.Name, signUpDto.ProfilePicUrl, roles)
 	if err != nil {
 		return nil, err
 	}
@@ -118,7 +315,12 @@ func (s *service) SignUpBasic(signUpDto *dto.SignUpBasic) (*dto.UserAuth, error)
 		return nil, err
 	}
 
-	accessToken, refreshToken, err := s.GenerateToken(user)
+	// the user can ask for an other verification mail, so the sign up does not fail on it
+	if err := s.sendEmailVerification(user); err != nil {
+		s.logger.Error("could not send the email verification", "user", user.ID.Hex(), "error", err)
+	}
+
+	accessToken, refreshToken, err := s.GenerateToken(user, device)
 	if err != nil {
 		return nil, err
 	}
@@ -127,30 +329,65 @@ func (s *service) SignUpBasic(signUpDto *dto.SignUpBasic) (*dto.UserAuth, error)
 	return dto.NewUserAuth(user, tokens), nil
 }
 
-func (s *service) SignInBasic(signInDto *dto.SignInBasic) (*dto.UserAuth, error) {
+// the unknown emails, the users without a password and the wrong passwords get the same error
+func (s *service) SignInBasic(signInDto *dto.SignInBasic, device *model.Device) (*dto.UserAuth, *dto.MFAChallenge, error) {
+	if err := s.checkSignIn(signInDto.Email, device.IP); err != nil {
+		return nil, nil, err
+	}
+
 	user, err := s.userService.FindUserByEmail(signInDto.Email)
-	if err != nil {
-		return nil, network.NewNotFoundError("user not registerd", err)
+	if err != nil && !errors.Is(err, mongod.ErrNoDocuments) {
+		return nil, nil, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(signInDto.Password))
+	// the users of the identity providers have no password
+	hash := s.dummyPasswordHash
+	if user != nil && user.Password != nil {
+		hash = *user.Password
+	}
+
+	valid := s.verifyPassword(signInDto.Password, hash)
+	if !valid || user == nil || user.Password == nil {
+		return nil, nil, s.failSignIn(user, signInDto.Email, device.IP)
+	}
+
+	s.rehashPassword(user, signInDto.Password)
+
+	auth, challenge, err := s.signIn(user, device)
+	// the failures are kept till the second factor, they count its wrong codes as well
+	if err == nil && challenge == nil {
+		s.resetSignInLockout(signInDto.Email)
+	}
+	return auth, challenge, err
+}
+
+// the users with mfa enabled get a challenge for the second factor instead of the tokens
+func (s *service) signIn(user *userModel.User, device *model.Device) (*dto.UserAuth, *dto.MFAChallenge, error) {
+	enabled, err := s.isMFAEnabled(user.ID)
 	if err != nil {
-		return nil, network.NewUnauthorizedError("wrong password", err)
+		return nil, nil, err
 	}
 
-	accessToken, refreshToken, err := s.GenerateToken(user)
+	if enabled {
+		challenge, err := s.createMFAChallenge(user)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, challenge, nil
+	}
+
+	accessToken, refreshToken, err := s.GenerateToken(user, device)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	tokens := dto.NewUserTokens(accessToken, refreshToken)
-	return dto.NewUserAuth(user, tokens), nil
+	return dto.NewUserAuth(user, tokens), nil, nil
 }
 
+// the whole token family is signed out, including the rotated keystores
 func (s *service) SignOut(keystore *model.Keystore) error {
-	filter := bson.M{"_id": keystore.ID}
-	_, err := s.keystoreQueryBuilder.SingleQuery().DeleteOne(filter)
-	return err
+	return s.RevokeKeystoreFamily(keystore)
 }
 
 func (s *service) IsEmailRegisted(email string) bool {
@@ -174,7 +411,8 @@ func (s *service) RenewToken(tokenRefreshDto *dto.TokenRefresh, accessToken stri
 		return nil, err
 	}
 
-	valid = s.ValidateClaims(refreshClaims)
+	// the tokens issued before the scopes were added have none
+	valid = s.ValidateClaims(refreshClaims) && (refreshClaims.Scope == "" || refreshClaims.HasScope(model.ScopeRefresh))
 	if !valid {
 		return nil, network.NewUnauthorizedError("permission denied: invalid refresh claims", nil)
 	}
@@ -191,15 +429,25 @@ func (s *service) RenewToken(tokenRefreshDto *dto.TokenRefresh, accessToken stri
 
 	keystore, err := s.FindRefreshKeystore(user, accessClaims.ID, refreshClaims.ID)
 	if err != nil {
-		return nil, network.NewUnauthorizedError("permission denied: claims ids", nil)
+		return nil, s.detectRefreshTokenReuse(user, accessClaims.ID, refreshClaims.ID)
 	}
 
-	err = s.SignOut(keystore)
+	err = s.checkKeystoreIdle(keystore, time.Now())
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 
-	accessToken, refreshToken, err := s.GenerateToken(user)
+	// only one of the concurrent refreshes with the same token can rotate it
+	// the others are rejected, a client retrying its own request must not lose the session
+	rotated, err := s.RotateKeystore(keystore)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, network.NewUnauthorizedError("permission denied: refresh token already used", nil)
+	}
+
+	accessToken, refreshToken, err := s.generateToken(user, nil, keystore, false)
 	if err != nil {
 		return nil, err
 	}
@@ -207,7 +455,12 @@ func (s *service) RenewToken(tokenRefreshDto *dto.TokenRefresh, accessToken stri
 	return dto.NewUserTokens(accessToken, refreshToken), nil
 }
 
-func (s *service) GenerateToken(user *userModel.User) (string, string, error) {
+func (s *service) GenerateToken(user *userModel.User, device *model.Device) (string, string, error) {
+	return s.generateToken(user, device, nil, false)
+}
+
+// mfa marks a new session signed in with a second factor, a child keystore inherits it
+func (s *service) generateToken(user *userModel.User, device *model.Device, parent *model.Keystore, mfa bool) (string, string, error) {
 	primaryKey, err := utils.GenerateRandomString(32)
 	if err != nil {
 		return "", "", err
@@ -217,11 +470,17 @@ func (s *service) GenerateToken(user *userModel.User) (string, string, error) {
 		return "", "", err
 	}
 
-	_, err = s.CreateKeystore(user, primaryKey, secondaryKey)
+	keystore, err := s.createKeystore(user, primaryKey, secondaryKey, device, parent, mfa)
 	if err != nil {
 		return "", "", err
 	}
 
+	roles := make([]string, len(user.RoleDocs))
+	for i, role := range user.RoleDocs {
+		roles[i] = string(role.Code)
+	}
+	session := keystore.SessionID().Hex()
+
 	now := jwt.NewNumericDate(time.Now())
 
 	accessTokenClaims := jwt.RegisteredClaims{
@@ -244,12 +503,18 @@ func (s *service) GenerateToken(user *userModel.User) (string, string, error) {
 		ID:        secondaryKey,
 	}
 
-	accessToken, err := s.SignToken(accessTokenClaims)
+	accessClaims := model.NewClaims(accessTokenClaims, roles, session, model.ScopeAccess)
+	accessClaims.EmailVerified = user.Verified
+	if keystore.MFA {
+		accessClaims.AMR = []string{model.AMRMFA}
+	}
+
+	accessToken, err := s.SignToken(accessClaims)
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshToken, err := s.SignToken(refreshTokenClaims)
+	refreshToken, err := s.SignToken(model.NewClaims(refreshTokenClaims, nil, session, model.ScopeRefresh))
 	if err != nil {
 		return "", "", err
 	}
@@ -257,11 +522,33 @@ func (s *service) GenerateToken(user *userModel.User) (string, string, error) {
 	return accessToken, refreshToken, nil
 }
 
-func (s *service) CreateKeystore(client *userModel.User, primaryKey string, secondaryKey string) (*model.Keystore, error) {
-	doc, err := model.NewKeystore(client.ID, primaryKey, secondaryKey)
+func (s *service) CreateKeystore(
+	client *userModel.User,
+	primaryKey string,
+	secondaryKey string,
+	device *model.Device,
+	parent *model.Keystore,
+) (*model.Keystore, error) {
+	return s.createKeystore(client, primaryKey, secondaryKey, device, parent, false)
+}
+
+func (s *service) createKeystore(
+	client *userModel.User,
+	primaryKey string,
+	secondaryKey string,
+	device *model.Device,
+	parent *model.Keystore,
+	mfa bool,
+) (*model.Keystore, error) {
+	// the keystore lives as long as its refresh token
+	expiresAt := time.Now().Add(s.refreshTokenValidity * time.Second)
+	doc, err := model.NewKeystore(client.ID, primaryKey, secondaryKey, expiresAt, device, parent)
 	if err != nil {
 		return nil, err
 	}
+	if mfa {
+		doc.MFA = true
+	}
 
 	id, err := s.keystoreQueryBuilder.SingleQuery().InsertOne(doc)
 	if err != nil {
@@ -282,25 +569,209 @@ func (s *service) FindRefreshKeystore(client *userModel.User, primaryKey string,
 	return s.keystoreQueryBuilder.SingleQuery().FindOne(filter, nil)
 }
 
-func (s *service) SignToken(claims jwt.RegisteredClaims) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	signed, err := token.SignedString(s.rsaPrivateKey)
+func (s *service) FindRotatedKeystore(client *userModel.User, primaryKey string, secondaryKey string) (*model.Keystore, error) {
+	filter := bson.M{"client": client.ID, "pKey": primaryKey, "sKey": secondaryKey, "rotatedAt": bson.M{"$exists": true}}
+	return s.keystoreQueryBuilder.SingleQuery().FindOne(filter, nil)
+}
+
+// rotated keystores are kept disabled to detect the reuse of their refresh token
+func (s *service) RotateKeystore(keystore *model.Keystore) (bool, error) {
+	// revoked first, so that a failure leaves the keystore usable for a retry
+	if err := s.revokeAccessTokens(keystore); err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	filter := bson.M{"_id": keystore.ID, "status": true}
+	update := bson.M{"$set": bson.M{"status": false, "rotatedAt": now, "updatedAt": now}}
+	result, err := s.keystoreQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (s *service) RevokeKeystoreFamily(keystore *model.Keystore) error {
+	// keystores created before the token families have no family
+	if keystore.Family.IsZero() {
+		_, err := s.revokeKeystores(bson.M{"_id": keystore.ID})
+		return err
+	}
+	filter := bson.M{"client": keystore.Client, "family": keystore.Family}
+	_, err := s.revokeKeystores(filter)
+	return err
+}
+
+// the access tokens of the keystores are revoked as well, since the stateless
+// verification does not look up the keystores
+func (s *service) revokeKeystores(filter bson.M) (int64, error) {
+	keystores, err := s.keystoreQueryBuilder.SingleQuery().FindAll(filter, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.revokeAccessTokens(keystores...); err != nil {
+		return 0, err
+	}
+
+	result, err := s.keystoreQueryBuilder.SingleQuery().DeleteMany(filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// the access token id is the primary key of its keystore
+func (s *service) revokeAccessTokens(keystores ...*model.Keystore) error {
+	now := time.Now()
+	for _, keystore := range keystores {
+		if keystore.IsRotated() && keystore.RotatedAt.Before(now.Add(-s.accessTokenValidity*time.Second)) {
+			// its access token has already expired
+			continue
+		}
+		err := s.revokedTokenCache.SetJSON(revokedTokenCacheKey(keystore.PrimaryKey), &now, s.accessTokenValidity*time.Second)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) IsTokenRevoked(claims *model.Claims) (bool, error) {
+	_, err := s.revokedTokenCache.GetJSON(revokedTokenCacheKey(claims.ID))
+	if redis.IsNil(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// the single use tokens are claimed at once, only the first of the concurrent uses gets it
+func (s *service) claimToken(claims *model.Claims) (bool, error) {
+	now := time.Now()
+	return s.revokedTokenCache.SetJSONNX(revokedTokenCacheKey(claims.ID), &now, claims.ExpiresAt.Sub(now))
+}
+
+func revokedTokenCacheKey(id string) string {
+	return "revoked_token_" + id
+}
+
+// the activity is written at most once per throttle interval
+func (s *service) TouchKeystore(keystore *model.Keystore) error {
+	now := time.Now()
+	if err := s.checkKeystoreIdle(keystore, now); err != nil {
+		return err
+	}
+
+	if keystore.LastUsedAt != nil && now.Sub(*keystore.LastUsedAt) < s.sessionLastUsedThrottle {
+		return nil
+	}
+
+	filter := bson.M{"_id": keystore.ID}
+	update := bson.M{"$set": bson.M{"lastUsedAt": now}}
+	_, err := s.keystoreQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if err != nil {
+		// the activity is informative, the request should not fail on it
+		s.logger.Warn("could not update the session last used", "keystore", keystore.ID.Hex(), "error", err)
+		return nil
+	}
+
+	keystore.LastUsedAt = &now
+	return nil
+}
+
+// idle sessions are signed out
+func (s *service) checkKeystoreIdle(keystore *model.Keystore, now time.Time) error {
+	if s.sessionIdleTimeout == 0 || !keystore.IsIdle(s.sessionIdleTimeout, now) {
+		return nil
+	}
+
+	if err := s.RevokeKeystoreFamily(keystore); err != nil {
+		return err
+	}
+
+	return network.NewUnauthorizedError("permission denied: session expired due to inactivity", nil)
+}
+
+func (s *service) GetSessions(client *userModel.User, current *model.Keystore) ([]*dto.InfoSession, error) {
+	filter := bson.M{"client": client.ID, "status": true}
+	opts := options.Find().SetSort(bson.D{{Key: "signedInAt", Value: -1}})
+	keystores, err := s.keystoreQueryBuilder.SingleQuery().FindAll(filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*dto.InfoSession, len(keystores))
+	for i, keystore := range keystores {
+		isCurrent := current != nil && keystore.SessionID() == current.SessionID()
+		sessions[i] = dto.NewInfoSession(keystore, isCurrent)
+	}
+
+	return sessions, nil
+}
+
+func (s *service) GetUserSessions(userId primitive.ObjectID) ([]*dto.InfoSession, error) {
+	user, err := s.userService.FindUserById(userId)
+	if err != nil {
+		return nil, network.NewNotFoundError("user not found", err)
+	}
+	return s.GetSessions(user, nil)
+}
+
+func (s *service) RevokeSession(client *userModel.User, sessionId primitive.ObjectID) error {
+	filter := bson.M{
+		"client": client.ID,
+		"$or":    bson.A{bson.M{"family": sessionId}, bson.M{"_id": sessionId}},
+	}
+	deleted, err := s.revokeKeystores(filter)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return network.NewNotFoundError("session not found", nil)
+	}
+	return nil
+}
+
+func (s *service) RevokeOtherSessions(current *model.Keystore) error {
+	filter := bson.M{"client": current.Client, "family": bson.M{"$ne": current.Family}}
+	if current.Family.IsZero() {
+		filter = bson.M{"client": current.Client, "_id": bson.M{"$ne": current.ID}}
+	}
+	_, err := s.revokeKeystores(filter)
+	return err
+}
+
+func (s *service) RevokeUserSessions(userId primitive.ObjectID) error {
+	user, err := s.userService.FindUserById(userId)
+	if err != nil {
+		return network.NewNotFoundError("user not found", err)
+	}
+	_, err = s.revokeKeystores(bson.M{"client": user.ID})
+	return err
+}
+
+func (s *service) SignToken(claims *model.Claims) (string, error) {
+	kid, key := s.keyRing.SigningKey()
+	token := jwt.NewWithClaims(s.signingMethod, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
 	if err != nil {
 		return "", err
 	}
 	return signed, nil
 }
 
-func (s *service) VerifyToken(tokenStr string) (*jwt.RegisteredClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(tkn *jwt.Token) (any, error) {
-		return s.rsaPublicKey, nil
-	})
+func (s *service) VerifyToken(tokenStr string) (*model.Claims, error) {
+	token, err := s.parseToken(tokenStr)
 	if err != nil {
 		return nil, err
 	}
 
 	if token.Valid {
-		if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok {
+		if claims, ok := token.Claims.(*model.Claims); ok {
 			return claims, nil
 		}
 	}
@@ -308,22 +779,45 @@ func (s *service) VerifyToken(tokenStr string) (*jwt.RegisteredClaims, error) {
 	return nil, jwt.ErrTokenMalformed
 }
 
-func (s *service) DecodeToken(tokenStr string) (*jwt.RegisteredClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(tkn *jwt.Token) (any, error) {
-		return s.rsaPublicKey, nil
-	})
-	if token == nil {
+// the token must be signed by the ring, only its expiry is not checked
+func (s *service) DecodeToken(tokenStr string) (*model.Claims, error) {
+	token, err := s.parseToken(tokenStr)
+	if err != nil && !errors.Is(err, jwt.ErrTokenExpired) {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok {
+	if claims, ok := token.Claims.(*model.Claims); ok {
 		return claims, nil
 	}
 
 	return nil, jwt.ErrTokenMalformed
 }
 
-func (s *service) ValidateClaims(claims *jwt.RegisteredClaims) bool {
+// only the configured alg is accepted, a token can not pick its own alg
+// to be verified with a key of an other type
+func (s *service) parseToken(tokenStr string) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(
+		tokenStr,
+		&model.Claims{},
+		s.verificationKey,
+		jwt.WithValidMethods([]string{s.signingMethod.Alg()}),
+	)
+}
+
+// the key is picked by the kid header, so the tokens signed by the retired keys stay valid
+func (s *service) verificationKey(token *jwt.Token) (any, error) {
+	if token.Method.Alg() != s.signingMethod.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	kid, _ := token.Header["kid"].(string)
+	return s.keyRing.VerificationKey(kid)
+}
+
+func (s *service) KeySet() *jwk.Set {
+	return s.keyRing.Set()
+}
+
+func (s *service) ValidateClaims(claims *model.Claims) bool {
 	invalid := claims.Issuer != s.tokenIssuer ||
 		claims.Subject == "" ||
 		len(claims.Audience) == 0 ||
@@ -339,25 +833,59 @@ func (s *service) ValidateClaims(claims *jwt.RegisteredClaims) bool {
 	return utils.IsValidObjectID(claims.Subject)
 }
 
+// looks up the local cache, then redis and then the database
+// unknown keys are cached as well, so that the invalid keys do not reach the database
 func (s *service) FindApiKey(key string) (*model.ApiKey, error) {
-	filter := bson.M{"key": key, "status": true}
+	hash := s.hashApiKey(key)
+	cacheKey := apikeyCacheKey(hash)
+
+	if s.apikeyLocalCache != nil {
+		if apikey, ok := s.apikeyLocalCache.Get(cacheKey); ok {
+			return foundApiKey(apikey)
+		}
+	}
 
-	apikey, err := s.apikeyQueryBuilder.SingleQuery().FindOne(filter, nil)
+	if s.apikeyCacheTTL > 0 {
+		apikey, err := s.apikeyCache.GetJSON(cacheKey)
+		if err == nil {
+			s.setLocalApiKey(cacheKey, apikey)
+			return foundApiKey(apikey)
+		}
+	}
+
+	apikey, err := s.findApiKeyByHash(key, hash)
+	if errors.Is(err, mongod.ErrNoDocuments) {
+		if s.apikeyNegativeCacheTTL > 0 {
+			unknown := &model.ApiKey{}
+			s.apikeyCache.SetJSON(cacheKey, unknown, s.apikeyNegativeCacheTTL)
+			s.setLocalApiKey(cacheKey, unknown)
+		}
+		return nil, err
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return apikey, nil
+	if s.apikeyCacheTTL > 0 {
+		s.apikeyCache.SetJSON(cacheKey, apikey, s.apikeyCacheTTL)
+	}
+	s.setLocalApiKey(cacheKey, apikey)
+
+	return foundApiKey(apikey)
 }
 
 func (s *service) CreateApiKey(key string, version int, permissions []model.Permission, comments []string) (*model.ApiKey, error) {
-	doc := model.NewApiKey(key, version, permissions, comments)
+	hash := s.hashApiKey(key)
+	doc := model.NewApiKey(apikeyPrefix(key), hash, version, permissions, comments)
 
 	id, err := s.apikeyQueryBuilder.SingleQuery().InsertOne(doc)
 	if err != nil {
 		return nil, err
 	}
 
+	// the key could have been cached as unknown
+	s.invalidateApiKey(hash)
+
 	doc.ID = *id
 	return doc, nil
 }
@@ -368,5 +896,291 @@ func (s *service) DeleteApiKey(apikey *model.ApiKey) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	s.invalidateApiKey(apikey.Hash)
 	return result.DeletedCount > 0, nil
 }
+
+func (s *service) IssueApiKey(d *dto.CreateApiKey, admin *userModel.User) (*dto.IssuedApiKey, error) {
+	if d.ExpiresAt != nil && !d.ExpiresAt.After(time.Now()) {
+		return nil, network.NewBadRequestError("expiresAt must be in the future", nil)
+	}
+
+	key, apikey, err := s.issueApiKey(d.Version, d.Versions, d.Permissions, d.Comments, d.ExpiresAt, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewIssuedApiKey(key, apikey)
+}
+
+func (s *service) issueApiKey(
+	version int,
+	versions []int,
+	permissions []model.Permission,
+	comments []string,
+	expiresAt *time.Time,
+	admin *userModel.User,
+) (string, *model.ApiKey, error) {
+	key, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if comments == nil {
+		comments = []string{}
+	}
+
+	doc := model.NewApiKey(apikeyPrefix(key), s.hashApiKey(key), version, permissions, comments)
+	doc.Versions = versions
+	doc.ExpiresAt = expiresAt
+	doc.CreatedBy = &admin.ID
+	doc.UpdatedBy = &admin.ID
+
+	id, err := s.apikeyQueryBuilder.SingleQuery().InsertOne(doc)
+	if err != nil {
+		return "", nil, err
+	}
+
+	doc.ID = *id
+	return key, doc, nil
+}
+
+func (s *service) GetPaginatedApiKeys(p *coredto.Pagination) ([]*dto.InfoApiKey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	apikeys, err := s.apikeyQueryBuilder.SingleQuery().FindPaginated(bson.M{}, p.Page, p.Limit, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]*dto.InfoApiKey, len(apikeys))
+	for i, k := range apikeys {
+		d, err := dto.NewInfoApiKey(k)
+		if err != nil {
+			return nil, err
+		}
+		dtos[i] = d
+	}
+
+	return dtos, nil
+}
+
+func (s *service) RevokeApiKey(id primitive.ObjectID, admin *userModel.User) error {
+	apikey, err := s.findApiKeyById(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"status": false, "updatedBy": admin.ID, "updatedAt": time.Now()}}
+	_, err = s.apikeyQueryBuilder.SingleQuery().UpdateOne(bson.M{"_id": apikey.ID}, update)
+	if err != nil {
+		return err
+	}
+
+	s.invalidateApiKey(apikey.Hash)
+	return nil
+}
+
+// issues a new key with the same access, the old key keeps working till the overlap ends
+func (s *service) RotateApiKey(id primitive.ObjectID, d *dto.RotateApiKey, admin *userModel.User) (*dto.IssuedApiKey, error) {
+	now := time.Now()
+	if d.ExpiresAt != nil && !d.ExpiresAt.After(now) {
+		return nil, network.NewBadRequestError("expiresAt must be in the future", nil)
+	}
+
+	old, err := s.findApiKeyById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !old.Status || old.IsExpired() {
+		return nil, network.NewBadRequestError("api key is already revoked or expired", nil)
+	}
+
+	if old.RotatedTo != nil {
+		return nil, network.NewBadRequestError("api key is already rotated to "+old.RotatedTo.Hex(), nil)
+	}
+
+	key, apikey, err := s.issueApiKey(old.Version, old.Versions, old.Permissions, old.Comments, d.ExpiresAt, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	overlapEnd := now.Add(d.Overlap())
+	if old.ExpiresAt != nil && old.ExpiresAt.Before(overlapEnd) {
+		overlapEnd = *old.ExpiresAt
+	}
+
+	update := bson.M{"$set": bson.M{
+		"expiresAt": overlapEnd,
+		"rotatedTo": apikey.ID,
+		"updatedBy": admin.ID,
+		"updatedAt": now,
+	}}
+	// only one of the concurrent rotations can link the old key, the new keys of the others are dropped
+	filter := bson.M{"_id": old.ID, "rotatedTo": bson.M{"$exists": false}}
+	result, err := s.apikeyQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if err == nil && result.ModifiedCount == 0 {
+		err = network.NewBadRequestError("api key is already rotated", nil)
+	}
+	if err != nil {
+		if _, dErr := s.apikeyQueryBuilder.SingleQuery().DeleteOne(bson.M{"_id": apikey.ID}); dErr != nil {
+			s.logger.Error("could not delete the unused rotated api key", "apikey", apikey.ID.Hex(), "error", dErr)
+		}
+		return nil, err
+	}
+
+	s.invalidateApiKey(old.Hash)
+	return dto.NewIssuedApiKey(key, apikey)
+}
+
+func (s *service) findApiKeyById(id primitive.ObjectID) (*model.ApiKey, error) {
+	apikey, err := s.apikeyQueryBuilder.SingleQuery().FindOne(bson.M{"_id": id}, nil)
+	if err != nil {
+		return nil, network.NewNotFoundError("api key not found", err)
+	}
+	return apikey, nil
+}
+
+// the prefix narrows the lookup, the hash is then compared in constant time
+func (s *service) findApiKeyByHash(key string, hash string) (*model.ApiKey, error) {
+	filter := bson.M{"prefix": apikeyPrefix(key), "status": true}
+	apikeys, err := s.apikeyQueryBuilder.SingleQuery().FindAll(filter, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, apikey := range apikeys {
+		if hmac.Equal([]byte(apikey.Hash), []byte(hash)) {
+			return apikey, nil
+		}
+	}
+
+	return nil, mongod.ErrNoDocuments
+}
+
+// migrates the api keys stored before the hashing, it is safe to run on every start
+func (s *service) HashPlaintextApiKeys() (int, error) {
+	collection := s.apikeyQueryBuilder.GetCollection()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// the keys are looked up by the prefix since the hashing, the old index only slows the writes
+	if _, err := collection.Indexes().DropOne(ctx, "code_1_status_1"); err != nil && !isIndexNotFound(err) {
+		s.logger.Warn("could not drop the api key code index", "error", err)
+	}
+
+	// nothing is left to migrate after the first run
+	filter := bson.M{"key": bson.M{"$exists": true}, "hash": bson.M{"$exists": false}}
+	pending, err := collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return 0, err
+	}
+	if pending == 0 {
+		return 0, nil
+	}
+
+	// the unique index on the plaintext key conflicts with the documents without it
+	if _, err := collection.Indexes().DropOne(ctx, "key_1"); err != nil && !isIndexNotFound(err) {
+		s.logger.Warn("could not drop the plaintext api key index", "error", err)
+	}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	count := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID  primitive.ObjectID `bson:"_id"`
+			Key string             `bson:"key"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return count, err
+		}
+
+		update := bson.M{
+			"$set":   bson.M{"prefix": apikeyPrefix(doc.Key), "hash": s.hashApiKey(doc.Key), "updatedAt": time.Now()},
+			"$unset": bson.M{"key": ""},
+		}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": doc.ID}, update); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, cursor.Err()
+}
+
+func isIndexNotFound(err error) bool {
+	var cmdErr mongod.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.Name == "IndexNotFound"
+}
+
+// the keystores created before the ttl index have no expiry, they get the one of their refresh token
+// it does nothing once all of them have it
+func (s *service) BackfillKeystoreExpiry() (int64, error) {
+	collection := s.keystoreQueryBuilder.GetCollection()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	validity := (s.refreshTokenValidity * time.Second).Milliseconds()
+	filter := bson.M{"expiresAt": bson.M{"$exists": false}}
+	update := bson.A{bson.M{"$set": bson.M{"expiresAt": bson.M{"$add": bson.A{"$createdAt", validity}}}}}
+
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (s *service) hashApiKey(key string) string {
+	mac := hmac.New(sha256.New, s.apikeyHashSecret)
+	mac.Write([]byte(key))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func apikeyPrefix(key string) string {
+	if len(key) > apikeyPrefixLength {
+		return key[:apikeyPrefixLength]
+	}
+	return key
+}
+
+// must be called after every change of an api key
+// the local caches of the other instances expire with their ttl
+func (s *service) invalidateApiKey(hash string) {
+	cacheKey := apikeyCacheKey(hash)
+	if s.apikeyLocalCache != nil {
+		s.apikeyLocalCache.Delete(cacheKey)
+	}
+	s.apikeyCache.Delete(cacheKey)
+}
+
+func (s *service) setLocalApiKey(cacheKey string, apikey *model.ApiKey) {
+	if s.apikeyLocalCache != nil {
+		s.apikeyLocalCache.Set(cacheKey, apikey)
+	}
+}
+
+func apikeyCacheKey(hash string) string {
+	return "apikey_" + hash
+}
+
+const apikeyPrefixLength = 8
+
+var errApiKeyExpired = errors.New("api key expired")
+
+// an api key without id is the cached entry of an unknown key
+func foundApiKey(apikey *model.ApiKey) (*model.ApiKey, error) {
+	if apikey.ID.IsZero() {
+		return nil, mongod.ErrNoDocuments
+	}
+	if apikey.IsExpired() {
+		return nil, errApiKeyExpired
+	}
+	found := *apikey
+	return &found, nil
+}
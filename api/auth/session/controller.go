@@ -0,0 +1,114 @@
+package session
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/api/auth"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	coredto "github.com/unusualcodeorg/goserve/arch/dto"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+)
+
+type controller struct {
+	network.BaseController
+	common.ContextPayload
+	service auth.Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service auth.Service,
+) network.Controller {
+	return &controller{
+		BaseController: network.NewBaseController("/auth/session", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(c.Authentication())
+	group.GET("/", c.getSessionsHandler)
+	group.DELETE("/id/:id", c.revokeSessionHandler)
+	group.DELETE("/others", c.revokeOtherSessionsHandler)
+
+	admin := group.Group("/user", c.Authorization(string(userModel.RoleCodeAdmin)))
+	admin.GET("/id/:id", c.getUserSessionsHandler)
+	admin.DELETE("/id/:id", c.revokeUserSessionsHandler)
+}
+
+func (c *controller) getSessionsHandler(ctx *gin.Context) {
+	user := c.MustGetUser(ctx)
+	keystore := c.MustGetKeystore(ctx)
+
+	data, err := c.service.GetSessions(user, keystore)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("success", data)
+}
+
+func (c *controller) revokeSessionHandler(ctx *gin.Context) {
+	mongoId, err := network.ReqParams(ctx, coredto.EmptyMongoId())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	err = c.service.RevokeSession(user, mongoId.ID)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessMsgResponse("session revoked successfully")
+}
+
+func (c *controller) revokeOtherSessionsHandler(ctx *gin.Context) {
+	keystore := c.MustGetKeystore(ctx)
+
+	err := c.service.RevokeOtherSessions(keystore)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessMsgResponse("other sessions revoked successfully")
+}
+
+func (c *controller) getUserSessionsHandler(ctx *gin.Context) {
+	mongoId, err := network.ReqParams(ctx, coredto.EmptyMongoId())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	data, err := c.service.GetUserSessions(mongoId.ID)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("success", data)
+}
+
+func (c *controller) revokeUserSessionsHandler(ctx *gin.Context) {
+	mongoId, err := network.ReqParams(ctx, coredto.EmptyMongoId())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	err = c.service.RevokeUserSessions(mongoId.ID)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessMsgResponse("user sessions revoked successfully")
+}
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/api/auth"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func mockProviders(user *userModel.User, keystore *model.Keystore) (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, user)
+		common.NewContextPayload().SetKeystore(ctx, keystore)
+		ctx.Next()
+	}))
+
+	mockAuthzProvider := new(network.MockAuthorizationProvider)
+	mockAuthzProvider.On("Middleware", []string{string(userModel.RoleCodeAdmin)}).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	return mockAuthProvider, mockAuthzProvider
+}
+
+func TestSessionController_GetSessions(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	keystore := &model.Keystore{ID: primitive.NewObjectID(), Client: user.ID, Family: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider := mockProviders(user, keystore)
+
+	sessions := []*dto.InfoSession{{ID: keystore.Family, UserAgent: "test-agent", IP: "10.0.0.1", Current: true}}
+
+	authService := new(auth.MockService)
+	authService.On("GetSessions", user, keystore).Return(sessions, nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, authService)
+
+	rr := network.MockTestController(t, "GET", "/auth/session/", "", c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"userAgent":"test-agent"`)
+	assert.Contains(t, rr.Body.String(), `"current":true`)
+}
+
+func TestSessionController_RevokeNotFound(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider := mockProviders(user, &model.Keystore{})
+
+	id := primitive.NewObjectID()
+
+	authService := new(auth.MockService)
+	authService.On("RevokeSession", user, id).Return(network.NewNotFoundError("session not found", nil))
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, authService)
+
+	rr := network.MockTestController(t, "DELETE", "/auth/session/id/"+id.Hex(), "", c)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"session not found"`)
+}
+
+func TestSessionController_RevokeOthers(t *testing.T) {
+	user := &userModel.User{ID: primitive.NewObjectID()}
+	keystore := &model.Keystore{ID: primitive.NewObjectID(), Client: user.ID, Family: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider := mockProviders(user, keystore)
+
+	authService := new(auth.MockService)
+	authService.On("RevokeOtherSessions", keystore).Return(nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, authService)
+
+	rr := network.MockTestController(t, "DELETE", "/auth/session/others", "", c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"other sessions revoked successfully"`)
+}
+
+func TestSessionController_RevokeUserSessions(t *testing.T) {
+	admin := &userModel.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider := mockProviders(admin, &model.Keystore{})
+
+	id := primitive.NewObjectID()
+
+	authService := new(auth.MockService)
+	authService.On("RevokeUserSessions", id).Return(nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, authService)
+
+	rr := network.MockTestController(t, "DELETE", "/auth/session/user/id/"+id.Hex(), "", c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"user sessions revoked successfully"`)
+	mockAuthzProvider.AssertCalled(t, "Middleware", []string{string(userModel.RoleCodeAdmin)})
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	userDto "github.com/unusualcodeorg/goserve/api/user/dto"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/mailer"
+	"github.com/unusualcodeorg/goserve/arch/mongo"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/utils"
+)
+
+const mailTimeout = 30 * time.Second
+
+func (s *service) SendEmailVerification(user *userModel.User) error {
+	// the user of the stateless authentication has no email
+	user, err := s.userService.FindUserById(user.ID)
+	if err != nil {
+		return network.NewNotFoundError("user not found", err)
+	}
+
+	if user.Verified {
+		return network.NewBadRequestError("email already verified", nil)
+	}
+
+	return s.sendEmailVerification(user)
+}
+
+// the token is bound to the email, so it is void once the email changes
+func (s *service) sendEmailVerification(user *userModel.User) error {
+	id, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	claims := model.NewClaims(jwt.RegisteredClaims{
+		Issuer:    s.tokenIssuer,
+		Subject:   user.ID.Hex(),
+		Audience:  []string{s.tokenAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(s.emailVerificationValidity)),
+		ID:        id,
+	}, nil, "", model.ScopeVerifyEmail)
+	claims.Email = user.Email
+
+	token, err := s.SignToken(claims)
+	if err != nil {
+		return err
+	}
+
+	mail := &mailer.Mail{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease verify your email by opening the link below:\n\n%s%s\n\n"+
+				"The link expires in %s. If you did not sign up, you can ignore this email.\n",
+			user.Name, s.emailVerificationURL, token, s.emailVerificationValidity,
+		),
+	}
+
+	return s.sendMail(mail)
+}
+
+// the current password is required and the other sessions are signed out, so a stolen session can not take the account
+// the email stays changed if the mails fail, the verification can be sent again, so only whether it was sent is returned
+func (s *service) ChangeUserEmail(user *userModel.User, current *model.Keystore, d *userDto.ChangeEmail) (*userDto.InfoPrivateUser, bool, error) {
+	account, err := s.checkUserPassword(user.ID, d.Password)
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err := s.userService.ChangeUserEmail(user, d)
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = s.RevokeOtherSessions(current)
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = s.sendEmailChanged(account, d.Email)
+	if err != nil {
+		s.logger.Error("could not send the email changed notice", "user", user.ID.Hex(), "error", err)
+	}
+
+	err = s.SendEmailVerification(user)
+	if err != nil {
+		s.logger.Error("could not send the email verification", "user", user.ID.Hex(), "error", err)
+		return data, false, nil
+	}
+
+	return data, true, nil
+}
+
+// sent to the old email, so that the owner notices a change made with a stolen session
+func (s *service) sendEmailChanged(user *userModel.User, newEmail string) error {
+	mail := &mailer.Mail{
+		To:      user.Email,
+		Subject: "Your email was changed",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe email of your account was changed to %s.\n"+
+				"If it was not you, please reset your password and contact the support.\n",
+			user.Name, newEmail,
+		),
+	}
+
+	return s.sendMail(mail)
+}
+
+func (s *service) sendMail(mail *mailer.Mail) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+	return s.mailer.Send(ctx, mail)
+}
+
+// the tokens are single use, they are claimed in the denylist and kept there till they expire
+// the access tokens carry the verified flag only after the next refresh
+func (s *service) VerifyEmail(d *dto.VerifyEmail) error {
+	claims, err := s.VerifyToken(d.Token)
+	if err != nil {
+		return network.NewBadRequestError("invalid verification token", err)
+	}
+
+	valid := s.ValidateClaims(claims) && claims.HasScope(model.ScopeVerifyEmail) && claims.Email != ""
+	if !valid {
+		return network.NewBadRequestError("invalid verification token", nil)
+	}
+
+	claimed, err := s.claimToken(claims)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return network.NewBadRequestError("verification token already used", nil)
+	}
+
+	userId, _ := mongo.NewObjectID(claims.Subject)
+	verified, err := s.userService.MarkUserVerified(userId, claims.Email)
+	if err != nil {
+		// released, so that the token can be used again once the database is back
+		if dErr := s.revokedTokenCache.Delete(revokedTokenCacheKey(claims.ID)); dErr != nil {
+			s.logger.Warn("could not release the email verification token", "user", claims.Subject, "error", dErr)
+		}
+		return err
+	}
+
+	if !verified {
+		user, err := s.userService.FindUserById(userId)
+		if err != nil {
+			return network.NewNotFoundError("user not found", err)
+		}
+		if user.Verified && user.Email == claims.Email {
+			return network.NewBadRequestError("email already verified", nil)
+		}
+		return network.NewBadRequestError("invalid verification token", nil)
+	}
+
+	return nil
+}
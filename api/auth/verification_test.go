@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/unusualcodeorg/goserve/api/auth/dto"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/api/user"
+	"github.com/unusualcodeorg/goserve/arch/logger"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/redis"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newVerifyEmailService(t *testing.T, userId primitive.ObjectID) (*service, *redis.MockCache[time.Time], *user.MockService, *dto.VerifyEmail) {
+	s := newTokenService(t, "RS256")
+	s.tokenIssuer = "api.goserve.test"
+	s.tokenAudience = "goserve.test"
+	s.logger = logger.NewDiscardLogger()
+
+	revokedCache := new(redis.MockCache[time.Time])
+	s.revokedTokenCache = revokedCache
+	userService := new(user.MockService)
+	s.userService = userService
+
+	now := time.Now()
+	claims := model.NewClaims(jwt.RegisteredClaims{
+		Issuer:    s.tokenIssuer,
+		Subject:   userId.Hex(),
+		Audience:  []string{s.tokenAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		ID:        "verify-id",
+	}, nil, "", model.ScopeVerifyEmail)
+	claims.Email = "test@abc.com"
+
+	token, err := s.SignToken(claims)
+	assert.NoError(t, err)
+
+	return s, revokedCache, userService, &dto.VerifyEmail{Token: token}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	userId := primitive.NewObjectID()
+	s, revokedCache, userService, d := newVerifyEmailService(t, userId)
+	revokedCache.On("SetJSONNX", revokedTokenCacheKey("verify-id"), mock.Anything, mock.Anything).Return(true, nil)
+	userService.On("MarkUserVerified", userId, "test@abc.com").Return(true, nil)
+
+	err := s.VerifyEmail(d)
+	assert.NoError(t, err)
+	userService.AssertExpectations(t)
+}
+
+func TestVerifyEmail_AlreadyUsed(t *testing.T) {
+	userId := primitive.NewObjectID()
+	s, revokedCache, userService, d := newVerifyEmailService(t, userId)
+	// an other request has claimed the token first
+	revokedCache.On("SetJSONNX", revokedTokenCacheKey("verify-id"), mock.Anything, mock.Anything).Return(false, nil)
+
+	err := s.VerifyEmail(d)
+
+	var apiError network.ApiError
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusBadRequest, apiError.GetCode())
+	assert.Equal(t, "verification token already used", apiError.GetMessage())
+	userService.AssertNotCalled(t, "MarkUserVerified", mock.Anything, mock.Anything)
+}
+
+func TestVerifyEmail_ReleasesTokenOnFailure(t *testing.T) {
+	userId := primitive.NewObjectID()
+	s, revokedCache, userService, d := newVerifyEmailService(t, userId)
+	dbErr := errors.New("db down")
+	revokedCache.On("SetJSONNX", revokedTokenCacheKey("verify-id"), mock.Anything, mock.Anything).Return(true, nil)
+	revokedCache.On("Delete", []string{revokedTokenCacheKey("verify-id")}).Return(nil)
+	userService.On("MarkUserVerified", userId, "test@abc.com").Return(false, dbErr)
+
+	err := s.VerifyEmail(d)
+	assert.Equal(t, dbErr, err)
+	revokedCache.AssertExpectations(t)
+}
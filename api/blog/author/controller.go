@@ -28,7 +28,8 @@ func NewController(
 }
 
 func (c *controller) MountRoutes(group *gin.RouterGroup) {
-	group.Use(c.Authentication(), c.Authorization(string(userModel.RoleCodeAuthor)))
+	// only the verified users can write, so that the blogs are not posted from the throwaway emails
+	group.Use(c.Authentication(), c.Authorization(userModel.VerifiedOption, string(userModel.RoleCodeAuthor)))
 	group.POST("/", c.postBlogHandler)
 	group.PUT("/", c.updateBlogHandler)
 	group.GET("/id/:id", c.getBlogHandler)
@@ -34,20 +34,20 @@ func (c *controller) getBlogByIdHandler(ctx *gin.Context) {
 		return
 	}
 
-	blog, err := c.service.GetBlogDtoCacheById(mongoId.ID)
+	blog, err := c.service.GetBlogDtoCacheById(ctx.Request.Context(), mongoId.ID)
 	if err == nil {
 		c.Send(ctx).SuccessDataResponse("success", blog)
 		return
 	}
 
-	blog, err = c.service.GetPublisedBlogById(mongoId.ID)
+	blog, err = c.service.GetPublisedBlogById(ctx.Request.Context(), mongoId.ID)
 	if err != nil {
 		c.Send(ctx).MixedError(err)
 		return
 	}
 
 	c.Send(ctx).SuccessDataResponse("success", blog)
-	c.service.SetBlogDtoCacheById(blog)
+	c.service.SetBlogDtoCacheById(ctx.Request.Context(), blog)
 }
 
 func (c *controller) getBlogBySlugHandler(ctx *gin.Context) {
@@ -57,18 +57,18 @@ func (c *controller) getBlogBySlugHandler(ctx *gin.Context) {
 		return
 	}
 
-	blog, err := c.service.GetBlogDtoCacheBySlug(slug.Slug)
+	blog, err := c.service.GetBlogDtoCacheBySlug(ctx.Request.Context(), slug.Slug)
 	if err == nil {
 		c.Send(ctx).SuccessDataResponse("success", blog)
 		return
 	}
 
-	blog, err = c.service.GetPublishedBlogBySlug(slug.Slug)
+	blog, err = c.service.GetPublishedBlogBySlug(ctx.Request.Context(), slug.Slug)
 	if err != nil {
 		c.Send(ctx).MixedError(err)
 		return
 	}
 
 	c.Send(ctx).SuccessDataResponse("success", blog)
-	c.service.SetBlogDtoCacheBySlug(blog)
+	c.service.SetBlogDtoCacheBySlug(ctx.Request.Context(), blog)
 }
@@ -1,6 +1,7 @@
 package blog
 
 import (
+	"context"
 	"time"
 
 	"github.com/unusualcodeorg/goserve/api/blog/dto"
@@ -16,14 +17,14 @@ import (
 )
 
 type Service interface {
-	SetBlogDtoCacheById(blog *dto.PublicBlog) error
-	GetBlogDtoCacheById(id primitive.ObjectID) (*dto.PublicBlog, error)
-	SetBlogDtoCacheBySlug(blog *dto.PublicBlog) error
-	GetBlogDtoCacheBySlug(slug string) (*dto.PublicBlog, error)
+	SetBlogDtoCacheById(ctx context.Context, blog *dto.PublicBlog) error
+	GetBlogDtoCacheById(ctx context.Context, id primitive.ObjectID) (*dto.PublicBlog, error)
+	SetBlogDtoCacheBySlug(ctx context.Context, blog *dto.PublicBlog) error
+	GetBlogDtoCacheBySlug(ctx context.Context, slug string) (*dto.PublicBlog, error)
 	BlogSlugExists(slug string) bool
-	GetPublisedBlogById(id primitive.ObjectID) (*dto.PublicBlog, error)
-	GetPublishedBlogBySlug(slug string) (*dto.PublicBlog, error)
-	getPublicPublishedBlog(filter bson.M) (*dto.PublicBlog, error)
+	GetPublisedBlogById(ctx context.Context, id primitive.ObjectID) (*dto.PublicBlog, error)
+	GetPublishedBlogBySlug(ctx context.Context, slug string) (*dto.PublicBlog, error)
+	getPublicPublishedBlog(ctx context.Context, filter bson.M) (*dto.PublicBlog, error)
 	getPaginated(filter bson.M, p *coredto.Pagination, opts *options.FindOptions) ([]*dto.InfoBlog, error)
 }
 
@@ -43,24 +44,24 @@ func NewService(db mongo.Database, store redis.Store, userService user.Service)
 	}
 }
 
-func (s *service) SetBlogDtoCacheById(blog *dto.PublicBlog) error {
+func (s *service) SetBlogDtoCacheById(ctx context.Context, blog *dto.PublicBlog) error {
 	key := "blog_" + blog.ID.Hex()
-	return s.publicBlogCache.SetJSON(key, blog, time.Duration(10*time.Minute))
+	return s.publicBlogCache.WithContext(ctx).SetJSON(key, blog, time.Duration(10*time.Minute))
 }
 
-func (s *service) GetBlogDtoCacheById(id primitive.ObjectID) (*dto.PublicBlog, error) {
+func (s *service) GetBlogDtoCacheById(ctx context.Context, id primitive.ObjectID) (*dto.PublicBlog, error) {
 	key := "blog_" + id.Hex()
-	return s.publicBlogCache.GetJSON(key)
+	return s.publicBlogCache.WithContext(ctx).GetJSON(key)
 }
 
-func (s *service) SetBlogDtoCacheBySlug(blog *dto.PublicBlog) error {
+func (s *service) SetBlogDtoCacheBySlug(ctx context.Context, blog *dto.PublicBlog) error {
 	key := "blog_" + blog.Slug
-	return s.publicBlogCache.SetJSON(key, blog, time.Duration(10*time.Minute))
+	return s.publicBlogCache.WithContext(ctx).SetJSON(key, blog, time.Duration(10*time.Minute))
 }
 
-func (s *service) GetBlogDtoCacheBySlug(slug string) (*dto.PublicBlog, error) {
+func (s *service) GetBlogDtoCacheBySlug(ctx context.Context, slug string) (*dto.PublicBlog, error) {
 	key := "blog_" + slug
-	return s.publicBlogCache.GetJSON(key)
+	return s.publicBlogCache.WithContext(ctx).GetJSON(key)
 }
 
 func (s *service) BlogSlugExists(slug string) bool {
@@ -71,20 +72,20 @@ func (s *service) BlogSlugExists(slug string) bool {
 	return err == nil
 }
 
-func (s *service) GetPublisedBlogById(id primitive.ObjectID) (*dto.PublicBlog, error) {
+func (s *service) GetPublisedBlogById(ctx context.Context, id primitive.ObjectID) (*dto.PublicBlog, error) {
 	filter := bson.M{"_id": id, "published": true, "status": true}
-	return s.getPublicPublishedBlog(filter)
+	return s.getPublicPublishedBlog(ctx, filter)
 }
 
-func (s *service) GetPublishedBlogBySlug(slug string) (*dto.PublicBlog, error) {
+func (s *service) GetPublishedBlogBySlug(ctx context.Context, slug string) (*dto.PublicBlog, error) {
 	filter := bson.M{"slug": slug, "published": true, "status": true}
-	return s.getPublicPublishedBlog(filter)
+	return s.getPublicPublishedBlog(ctx, filter)
 }
 
-func (s *service) getPublicPublishedBlog(filter bson.M) (*dto.PublicBlog, error) {
+func (s *service) getPublicPublishedBlog(ctx context.Context, filter bson.M) (*dto.PublicBlog, error) {
 	projection := bson.D{{Key: "draftText", Value: 0}}
 	opts := options.FindOne().SetProjection(projection)
-	blog, err := s.blogQueryBuilder.SingleQuery().FindOne(filter, opts)
+	blog, err := s.blogQueryBuilder.SingleQueryContext(ctx).FindOne(filter, opts)
 	if err != nil {
 		return nil, network.NewNotFoundError("blog not found", err)
 	}
@@ -9,22 +9,28 @@ import (
 
 type controller struct {
 	network.BaseController
-	service Service
+	rateLimitProvider network.RateLimitProvider
+	rateLimits        RateLimits
+	service           Service
 }
 
 func NewController(
 	authProvider network.AuthenticationProvider,
 	authorizeProvider network.AuthorizationProvider,
+	rateLimitProvider network.RateLimitProvider,
+	rateLimits RateLimits,
 	service Service,
 ) network.Controller {
 	return &controller{
-		BaseController: network.NewBaseController("/contact", authProvider, authorizeProvider),
-		service:        service,
+		BaseController:    network.NewBaseController("/contact", authProvider, authorizeProvider),
+		rateLimitProvider: rateLimitProvider,
+		rateLimits:        rateLimits,
+		service:           service,
 	}
 }
 
 func (c *controller) MountRoutes(group *gin.RouterGroup) {
-	group.POST("/", c.createMessageHandler)
+	group.POST("/", c.rateLimitProvider.Middleware(c.rateLimits.Contact), c.createMessageHandler)
 }
 
 func (c *controller) createMessageHandler(ctx *gin.Context) {
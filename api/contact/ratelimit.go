@@ -0,0 +1,25 @@
+package contact
+
+import (
+	"time"
+
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/config"
+)
+
+// limits of the contact routes, a limit without requests is disabled
+type RateLimits struct {
+	// the route is public, so it is limited per client ip
+	Contact network.RateLimit
+}
+
+func NewRateLimits(env *config.Env) RateLimits {
+	return RateLimits{
+		Contact: network.RateLimit{
+			Name:     "contact",
+			Requests: env.ContactRateLimitRequests,
+			Window:   time.Duration(env.ContactRateLimitWindowSec) * time.Second,
+			Key:      network.RateLimitByIP,
+		},
+	}
+}
@@ -2,26 +2,45 @@ package user
 
 import (
 	"github.com/gin-gonic/gin"
+	authModel "github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/api/user/dto"
+	"github.com/unusualcodeorg/goserve/api/user/model"
 	coredto "github.com/unusualcodeorg/goserve/arch/dto"
 	"github.com/unusualcodeorg/goserve/arch/network"
 	"github.com/unusualcodeorg/goserve/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// the account actions of the auth service, it is passed in since the auth package imports this one
+type AccountService interface {
+	ChangeUserEmail(user *model.User, current *authModel.Keystore, d *dto.ChangeEmail) (*dto.InfoPrivateUser, bool, error)
+	RevokeUserSessions(userId primitive.ObjectID) error
+}
+
 type controller struct {
 	network.BaseController
 	common.ContextPayload
-	service Service
+	rateLimitProvider network.RateLimitProvider
+	rateLimits        RateLimits
+	service           Service
+	accountService    AccountService
 }
 
 func NewController(
 	authProvider network.AuthenticationProvider,
 	authorizeProvider network.AuthorizationProvider,
+	rateLimitProvider network.RateLimitProvider,
+	rateLimits RateLimits,
 	service Service,
+	accountService AccountService,
 ) network.Controller {
 	return &controller{
-		BaseController: network.NewBaseController("/profile", authProvider, authorizeProvider),
-		ContextPayload: common.NewContextPayload(),
-		service:        service,
+		BaseController:    network.NewBaseController("/profile", authProvider, authorizeProvider),
+		ContextPayload:    common.NewContextPayload(),
+		rateLimitProvider: rateLimitProvider,
+		rateLimits:        rateLimits,
+		service:           service,
+		accountService:    accountService,
 	}
 }
 
@@ -29,6 +48,9 @@ func (c *controller) MountRoutes(group *gin.RouterGroup) {
 	group.GET("/id/:id", c.getPublicProfileHandler)
 	private := group.Use(c.Authentication())
 	private.GET("/mine", c.getPrivateProfileHandler)
+	private.PUT("/mine", c.updateProfileHandler)
+	private.PUT("/mine/email", c.rateLimitProvider.Middleware(c.rateLimits.ChangeEmail), c.changeEmailHandler)
+	private.DELETE("/mine", c.deactivateHandler)
 }
 
 func (c *controller) getPublicProfileHandler(ctx *gin.Context) {
@@ -57,4 +79,66 @@ func (c *controller) getPrivateProfileHandler(ctx *gin.Context) {
 	}
 
 	c.Send(ctx).SuccessDataResponse("success", data)
-}
\ No newline at end of file
+}
+
+func (c *controller) updateProfileHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptyUpdateProfile())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	data, err := c.service.UpdateUserProfile(user, body)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("profile updated", data)
+}
+
+func (c *controller) changeEmailHandler(ctx *gin.Context) {
+	body, err := network.ReqBody(ctx, dto.EmptyChangeEmail())
+	if err != nil {
+		c.Send(ctx).BadRequestError(err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+	keystore := c.MustGetKeystore(ctx)
+
+	data, mailed, err := c.accountService.ChangeUserEmail(user, keystore, body)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	if !mailed {
+		c.Send(ctx).SuccessDataResponse("email changed, the verification mail could not be sent, please resend it", data)
+		return
+	}
+
+	c.Send(ctx).SuccessDataResponse("email changed, please verify the new email", data)
+}
+
+// the sessions are signed out first, the auth service finds only the active users
+// the stateless access tokens are denylisted as well
+func (c *controller) deactivateHandler(ctx *gin.Context) {
+	user := c.MustGetUser(ctx)
+
+	err := c.accountService.RevokeUserSessions(user.ID)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	err = c.service.DeactivateUser(user.ID)
+	if err != nil {
+		c.Send(ctx).MixedError(err)
+		return
+	}
+
+	c.Send(ctx).SuccessMsgResponse("account deactivated")
+}
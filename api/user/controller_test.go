@@ -0,0 +1,168 @@
+package user
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	authModel "github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/api/user/dto"
+	"github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func mockProviders(user *model.User) (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider, *network.MockRateLimitProvider) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, user)
+		if user != nil {
+			common.NewContextPayload().SetKeystore(ctx, &authModel.Keystore{Client: user.ID, Status: true})
+		}
+		ctx.Next()
+	}))
+
+	mockAuthzProvider := new(network.MockAuthorizationProvider)
+
+	mockRateLimitProvider := new(network.MockRateLimitProvider)
+	mockRateLimitProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	return mockAuthProvider, mockAuthzProvider, mockRateLimitProvider
+}
+
+func TestUserController_UpdateProfileBadRequest(t *testing.T) {
+	user := &model.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, new(MockService), new(MockAccountService))
+
+	rr := network.MockTestController(t, "PUT", "/profile/mine", `{"name":""}`, c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"name must be at least 1 characters"`)
+
+	rr = network.MockTestController(t, "PUT", "/profile/mine", `{"profilePicUrl":"not a url"}`, c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"profilePicUrl must be a valid URL"`)
+}
+
+func TestUserController_UpdateProfile(t *testing.T) {
+	user := &model.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user)
+
+	name := "new name"
+	d := &dto.UpdateProfile{Name: &name}
+
+	userService := new(MockService)
+	userService.On("UpdateUserProfile", user, d).Return(&dto.InfoPrivateUser{ID: user.ID, Name: name}, nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, userService, new(MockAccountService))
+
+	rr := network.MockTestController(t, "PUT", "/profile/mine", `{"name":"new name"}`, c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"profile updated"`)
+	assert.Contains(t, rr.Body.String(), `"name":"new name"`)
+	userService.AssertExpectations(t)
+}
+
+func TestUserController_ChangeEmailBadRequest(t *testing.T) {
+	user := &model.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user)
+
+	accountService := new(MockAccountService)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, new(MockService), accountService)
+
+	rr := network.MockTestController(t, "PUT", "/profile/mine/email", `{"email":"new@abc.com"}`, c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"password is required"`)
+	accountService.AssertNotCalled(t, "ChangeUserEmail", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserController_ChangeEmailRegistered(t *testing.T) {
+	user := &model.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user)
+
+	d := &dto.ChangeEmail{Email: "taken@abc.com", Password: "123456"}
+
+	accountService := new(MockAccountService)
+	accountService.On("ChangeUserEmail", user, mock.AnythingOfType("*model.Keystore"), d).Return(nil, false, network.NewBadRequestError("email already registered", nil))
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, new(MockService), accountService)
+
+	rr := network.MockTestController(t, "PUT", "/profile/mine/email", `{"email":"taken@abc.com","password":"123456"}`, c)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"email already registered"`)
+}
+
+func TestUserController_ChangeEmail(t *testing.T) {
+	user := &model.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user)
+
+	d := &dto.ChangeEmail{Email: "new@abc.com", Password: "123456"}
+
+	accountService := new(MockAccountService)
+	accountService.On("ChangeUserEmail", user, mock.AnythingOfType("*model.Keystore"), d).Return(&dto.InfoPrivateUser{ID: user.ID, Email: d.Email}, true, nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, new(MockService), accountService)
+
+	rr := network.MockTestController(t, "PUT", "/profile/mine/email", `{"email":"new@abc.com","password":"123456"}`, c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"email changed, please verify the new email"`)
+	assert.Contains(t, rr.Body.String(), `"verified":false`)
+	accountService.AssertExpectations(t)
+}
+
+func TestUserController_ChangeEmailWrongPassword(t *testing.T) {
+	user := &model.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user)
+
+	d := &dto.ChangeEmail{Email: "new@abc.com", Password: "wrong"}
+
+	accountService := new(MockAccountService)
+	accountService.On("ChangeUserEmail", user, mock.AnythingOfType("*model.Keystore"), d).Return(nil, false, network.NewUnauthorizedError("wrong password", nil))
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, new(MockService), accountService)
+
+	rr := network.MockTestController(t, "PUT", "/profile/mine/email", `{"email":"new@abc.com","password":"wrong"}`, c)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"wrong password"`)
+}
+
+func TestUserController_ChangeEmailMailFailed(t *testing.T) {
+	user := &model.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user)
+
+	d := &dto.ChangeEmail{Email: "new@abc.com", Password: "123456"}
+
+	accountService := new(MockAccountService)
+	accountService.On("ChangeUserEmail", user, mock.AnythingOfType("*model.Keystore"), d).Return(&dto.InfoPrivateUser{ID: user.ID, Email: d.Email}, false, nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, new(MockService), accountService)
+
+	rr := network.MockTestController(t, "PUT", "/profile/mine/email", `{"email":"new@abc.com","password":"123456"}`, c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"email changed, the verification mail could not be sent, please resend it"`)
+}
+
+func TestUserController_Deactivate(t *testing.T) {
+	user := &model.User{ID: primitive.NewObjectID()}
+	mockAuthProvider, mockAuthzProvider, mockRateLimitProvider := mockProviders(user)
+
+	userService := new(MockService)
+	userService.On("DeactivateUser", user.ID).Return(nil)
+	accountService := new(MockAccountService)
+	accountService.On("RevokeUserSessions", user.ID).Return(nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, mockRateLimitProvider, RateLimits{}, userService, accountService)
+
+	rr := network.MockTestController(t, "DELETE", "/profile/mine", "", c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"account deactivated"`)
+	userService.AssertExpectations(t)
+	accountService.AssertExpectations(t)
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type ChangeEmail struct {
+	Email    string `json:"email" binding:"required" validate:"required,email,max=200"`
+	Password string `json:"password" binding:"required" validate:"required"`
+}
+
+func EmptyChangeEmail() *ChangeEmail {
+	return &ChangeEmail{}
+}
+
+func (d *ChangeEmail) GetValue() *ChangeEmail {
+	return d
+}
+
+func (d *ChangeEmail) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("%s is required", err.Field()))
+		case "email":
+			msgs = append(msgs, fmt.Sprintf("%s is not a valid email", err.Field()))
+		case "max":
+			msgs = append(msgs, fmt.Sprintf("%s must be at most %s characters", err.Field(), err.Param()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
@@ -14,6 +14,7 @@ type InfoPrivateUser struct {
 	Name          string             `json:"name" binding:"required" validate:"required"`
 	ProfilePicURL *string            `json:"profilePicUrl,omitempty" validate:"omitempty,url"`
 	Roles         []*InfoRole        `json:"roles" validate:"required,dive,required"`
+	Verified      bool               `json:"verified"`
 }
 
 func NewInfoPrivateUser(user *model.User) *InfoPrivateUser {
@@ -28,6 +29,7 @@ func NewInfoPrivateUser(user *model.User) *InfoPrivateUser {
 		Name:          user.Name,
 		ProfilePicURL: user.ProfilePicURL,
 		Roles:         roles,
+		Verified:      user.Verified,
 	}
 }
 
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// the fields left out are not changed, an empty profilePicUrl removes the picture
+type UpdateProfile struct {
+	Name          *string `json:"name" validate:"omitnil,min=1,max=200"`
+	ProfilePicURL *string `json:"profilePicUrl" validate:"omitempty,url,max=500"`
+}
+
+func EmptyUpdateProfile() *UpdateProfile {
+	return &UpdateProfile{}
+}
+
+func (d *UpdateProfile) GetValue() *UpdateProfile {
+	return d
+}
+
+func (d *UpdateProfile) ValidateErrors(errs validator.ValidationErrors) ([]string, error) {
+	var msgs []string
+	for _, err := range errs {
+		switch err.Tag() {
+		case "min":
+			msgs = append(msgs, fmt.Sprintf("%s must be at least %s characters", err.Field(), err.Param()))
+		case "max":
+			msgs = append(msgs, fmt.Sprintf("%s must be at most %s characters", err.Field(), err.Param()))
+		case "url":
+			msgs = append(msgs, fmt.Sprintf("%s must be a valid URL", err.Field()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s is invalid", err.Field()))
+		}
+	}
+	return msgs, nil
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"github.com/stretchr/testify/mock"
+	authModel "github.com/unusualcodeorg/goserve/api/auth/model"
 	"github.com/unusualcodeorg/goserve/api/user/dto"
 	"github.com/unusualcodeorg/goserve/api/user/model"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -59,6 +60,14 @@ func (m *MockService) FindUserByEmail(email string) (*model.User, error) {
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
+func (m *MockService) FindUserWithPasswordById(id primitive.ObjectID) (*model.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
 func (m *MockService) CreateUser(user *model.User) (*model.User, error) {
 	args := m.Called(user)
 	if args.Get(0) == nil {
@@ -87,3 +96,69 @@ func (m *MockService) DeleteUserByEmail(email string) (bool, error) {
 	args := m.Called(email)
 	return args.Bool(0), args.Error(1)
 }
+
+func (m *MockService) MarkUserVerified(userId primitive.ObjectID, email string) (bool, error) {
+	args := m.Called(userId, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockService) UpdateUserPassword(userId primitive.ObjectID, pwdHash string) (bool, error) {
+	args := m.Called(userId, pwdHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockService) ReplaceUserPassword(userId primitive.ObjectID, oldHash string, newHash string) (bool, error) {
+	args := m.Called(userId, oldHash, newHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockService) FindUserByIdentity(identity *model.Identity) (*model.User, error) {
+	args := m.Called(identity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockService) LinkUserIdentity(userId primitive.ObjectID, identity *model.Identity, dropPassword bool) error {
+	args := m.Called(userId, identity, dropPassword)
+	return args.Error(0)
+}
+
+func (m *MockService) UpdateUserProfile(user *model.User, d *dto.UpdateProfile) (*dto.InfoPrivateUser, error) {
+	args := m.Called(user, d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.InfoPrivateUser), args.Error(1)
+}
+
+func (m *MockService) ChangeUserEmail(user *model.User, d *dto.ChangeEmail) (*dto.InfoPrivateUser, error) {
+	args := m.Called(user, d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.InfoPrivateUser), args.Error(1)
+}
+
+func (m *MockService) DeactivateUser(userId primitive.ObjectID) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
+
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) ChangeUserEmail(user *model.User, current *authModel.Keystore, d *dto.ChangeEmail) (*dto.InfoPrivateUser, bool, error) {
+	args := m.Called(user, current, d)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*dto.InfoPrivateUser), args.Bool(1), args.Error(2)
+}
+
+func (m *MockAccountService) RevokeUserSessions(userId primitive.ObjectID) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -87,3 +88,15 @@ func (*Role) EnsureIndexes(db mongo.Database) {
 	}
 	mongo.NewQueryBuilder[Role](db, RolesCollectionName).Query(context.Background()).CreateIndexes(indexes)
 }
+
+// comma separated codes, e.g. of the env
+func ParseRoleCodes(codes string) []RoleCode {
+	var roles []RoleCode
+	for _, code := range strings.Split(codes, ",") {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code != "" {
+			roles = append(roles, RoleCode(code))
+		}
+	}
+	return roles
+}
@@ -14,13 +14,18 @@ import (
 
 const UserCollectionName = "users"
 
+// authorization option, the user email must be verified
+// it can be passed along with the roles or alone
+const VerifiedOption = "@verified"
+
 type User struct {
 	ID            primitive.ObjectID   `bson:"_id,omitempty"`
 	Name          string               `bson:"name" validate:"required,max=200"`
 	Email         string               `bson:"email" validate:"required,email"`
-	Password      *string              `bson:"password" validate:"required,min=6,max=100"`
+	Password      *string              `bson:"password,omitempty" validate:"omitempty,min=6,max=100"`
 	ProfilePicURL *string              `bson:"profilePicUrl,omitempty" validate:"omitempty,max=500"`
 	Roles         []primitive.ObjectID `bson:"roles,omitempty" validate:"required"`
+	Identities    []Identity           `bson:"identities,omitempty" validate:"-"`
 	Verified      bool                 `bson:"verified" validate:"-"`
 	Status        bool                 `bson:"status" validate:"-"`
 	CreatedAt     time.Time            `bson:"createdAt" validate:"required"`
@@ -30,16 +35,35 @@ type User struct {
 	RoleDocs []*Role `bson:"-" validate:"-"`
 }
 
+// the account of an identity provider
+type Identity struct {
+	Provider string `bson:"provider"`
+	Subject  string `bson:"subject"`
+}
+
+func NewIdentity(provider string, subject string) *Identity {
+	return &Identity{
+		Provider: provider,
+		Subject:  subject,
+	}
+}
+
+// an empty hash creates a user without a password, signed up with an identity provider
 func NewUser(email string, pwdHash string, name string, profilePicUrl *string, roles []*Role) (*User, error) {
 	roleIds := make([]primitive.ObjectID, len(roles))
 	for i, role := range roles {
 		roleIds[i] = role.ID
 	}
 
+	var password *string
+	if pwdHash != "" {
+		password = &pwdHash
+	}
+
 	now := time.Now()
 	u := User{
 		Email:         email,
-		Password:      &pwdHash,
+		Password:      password,
 		Name:          name,
 		ProfilePicURL: profilePicUrl,
 		Roles:         roleIds,
@@ -84,6 +108,12 @@ func (*User) EnsureIndexes(db mongo.Database) {
 			},
 			Options: options.Index().SetUnique(true),
 		},
+		{
+			Keys: bson.D{
+				{Key: "identities.provider", Value: 1},
+				{Key: "identities.subject", Value: 1},
+			},
+		},
 	}
 	mongo.NewQueryBuilder[User](db, UserCollectionName).Query(context.Background()).CreateIndexes(indexes)
 }
@@ -0,0 +1,26 @@
+package user
+
+import (
+	"time"
+
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/common"
+	"github.com/unusualcodeorg/goserve/config"
+)
+
+// limits of the profile routes, a limit without requests is disabled
+type RateLimits struct {
+	// the password is checked and the mails are sent, so it is limited per user
+	ChangeEmail network.RateLimit
+}
+
+func NewRateLimits(env *config.Env) RateLimits {
+	return RateLimits{
+		ChangeEmail: network.RateLimit{
+			Name:     "change_email",
+			Requests: env.ChangeEmailRateLimitRequests,
+			Window:   time.Duration(env.ChangeEmailRateLimitWindowSec) * time.Second,
+			Key:      common.RateLimitByUser,
+		},
+	}
+}
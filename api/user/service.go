@@ -1,12 +1,15 @@
 package user
 
 import (
+	"time"
+
 	"github.com/unusualcodeorg/goserve/api/user/dto"
 	"github.com/unusualcodeorg/goserve/api/user/model"
 	"github.com/unusualcodeorg/goserve/arch/mongo"
 	"github.com/unusualcodeorg/goserve/arch/network"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongod "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -17,10 +20,19 @@ type Service interface {
 	FindRoles(roleIds []primitive.ObjectID) ([]*model.Role, error)
 	FindUserById(id primitive.ObjectID) (*model.User, error)
 	FindUserByEmail(email string) (*model.User, error)
+	FindUserWithPasswordById(id primitive.ObjectID) (*model.User, error)
 	CreateUser(user *model.User) (*model.User, error)
 	FindUserPrivateProfile(user *model.User) (*model.User, error)
 	FindUserPublicProfile(userId primitive.ObjectID) (*model.User, error)
 	DeleteUserByEmail(email string) (bool, error)
+	MarkUserVerified(userId primitive.ObjectID, email string) (bool, error)
+	UpdateUserPassword(userId primitive.ObjectID, pwdHash string) (bool, error)
+	ReplaceUserPassword(userId primitive.ObjectID, oldHash string, newHash string) (bool, error)
+	FindUserByIdentity(identity *model.Identity) (*model.User, error)
+	LinkUserIdentity(userId primitive.ObjectID, identity *model.Identity, dropPassword bool) error
+	UpdateUserProfile(user *model.User, d *dto.UpdateProfile) (*dto.InfoPrivateUser, error)
+	ChangeUserEmail(user *model.User, d *dto.ChangeEmail) (*dto.InfoPrivateUser, error)
+	DeactivateUser(userId primitive.ObjectID) error
 }
 
 type service struct {
@@ -37,8 +49,13 @@ func NewService(db mongo.Database) Service {
 	}
 }
 
+// the user of the stateless authentication only has its id and roles
 func (s *service) GetUserPrivateProfile(user *model.User) (*dto.InfoPrivateUser, error) {
-	return dto.NewInfoPrivateUser(user), nil
+	profile, err := s.FindUserById(user.ID)
+	if err != nil {
+		return nil, network.NewNotFoundError("user does not exists", err)
+	}
+	return dto.NewInfoPrivateUser(profile), nil
 }
 
 func (s *service) GetUserPublicProfile(userId primitive.ObjectID) (*dto.InfoPublicUser, error) {
@@ -94,8 +111,29 @@ func (s *service) FindUserByEmail(email string) (*model.User, error) {
 	return user, nil
 }
 
+// the password is needed to check it, the other lookups by id project it out
+func (s *service) FindUserWithPasswordById(id primitive.ObjectID) (*model.User, error) {
+	filter := bson.M{"_id": id, "status": true}
+	user, err := s.userQueryBuilder.SingleQuery().FindOne(filter, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.FindRoles(user.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	user.RoleDocs = roles
+	return user, nil
+}
+
+// the emails of the deactivated users stay registered, the unique index covers them as well
 func (s *service) CreateUser(user *model.User) (*model.User, error) {
 	id, err := s.userQueryBuilder.SingleQuery().InsertOne(user)
+	if mongod.IsDuplicateKeyError(err) {
+		return nil, network.NewBadRequestError("email already registered", err)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -125,3 +163,138 @@ func (s *service) DeleteUserByEmail(email string) (bool, error) {
 	}
 	return result.DeletedCount > 0, nil
 }
+
+// the email must still be the one that was verified,
+// false when the user is missing or is already verified
+func (s *service) MarkUserVerified(userId primitive.ObjectID, email string) (bool, error) {
+	filter := bson.M{"_id": userId, "email": email, "verified": false, "status": true}
+	update := bson.M{"$set": bson.M{"verified": true, "updatedAt": time.Now()}}
+	result, err := s.userQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (s *service) UpdateUserPassword(userId primitive.ObjectID, pwdHash string) (bool, error) {
+	filter := bson.M{"_id": userId, "status": true}
+	update := bson.M{"$set": bson.M{"password": pwdHash, "updatedAt": time.Now()}}
+	result, err := s.userQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// the hash is replaced only if it is still the old one
+func (s *service) ReplaceUserPassword(userId primitive.ObjectID, oldHash string, newHash string) (bool, error) {
+	filter := bson.M{"_id": userId, "password": oldHash, "status": true}
+	update := bson.M{"$set": bson.M{"password": newHash}}
+	result, err := s.userQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (s *service) FindUserByIdentity(identity *model.Identity) (*model.User, error) {
+	filter := bson.M{
+		"identities": bson.M{"$elemMatch": bson.M{"provider": identity.Provider, "subject": identity.Subject}},
+		"status":     true,
+	}
+	proj := bson.D{{Key: "password", Value: 0}}
+	opts := options.FindOne().SetProjection(proj)
+	user, err := s.userQueryBuilder.SingleQuery().FindOne(filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.FindRoles(user.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	user.RoleDocs = roles
+	return user, nil
+}
+
+// the provider verified the email, so the user is marked verified as well
+func (s *service) LinkUserIdentity(userId primitive.ObjectID, identity *model.Identity, dropPassword bool) error {
+	update := bson.M{
+		"$addToSet": bson.M{"identities": identity},
+		"$set":      bson.M{"verified": true, "updatedAt": time.Now()},
+	}
+	if dropPassword {
+		update["$unset"] = bson.M{"password": ""}
+	}
+	_, err := s.userQueryBuilder.SingleQuery().UpdateOne(bson.M{"_id": userId}, update)
+	return err
+}
+
+func (s *service) UpdateUserProfile(user *model.User, d *dto.UpdateProfile) (*dto.InfoPrivateUser, error) {
+	set := bson.M{"updatedAt": time.Now()}
+	update := bson.M{"$set": set}
+
+	if d.Name != nil {
+		set["name"] = *d.Name
+	}
+
+	if d.ProfilePicURL != nil {
+		if *d.ProfilePicURL == "" {
+			update["$unset"] = bson.M{"profilePicUrl": ""}
+		} else {
+			set["profilePicUrl"] = *d.ProfilePicURL
+		}
+	}
+
+	filter := bson.M{"_id": user.ID, "status": true}
+	result, err := s.userQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, network.NewNotFoundError("user does not exists", nil)
+	}
+
+	return s.GetUserPrivateProfile(user)
+}
+
+// the new email must be verified again, the unique index rejects the registered ones
+func (s *service) ChangeUserEmail(user *model.User, d *dto.ChangeEmail) (*dto.InfoPrivateUser, error) {
+	current, err := s.FindUserById(user.ID)
+	if err != nil {
+		return nil, network.NewNotFoundError("user does not exists", err)
+	}
+	if current.Email == d.Email {
+		return nil, network.NewBadRequestError("email is not changed", nil)
+	}
+
+	filter := bson.M{"_id": user.ID, "status": true}
+	update := bson.M{"$set": bson.M{"email": d.Email, "verified": false, "updatedAt": time.Now()}}
+	result, err := s.userQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if mongod.IsDuplicateKeyError(err) {
+		return nil, network.NewBadRequestError("email already registered", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, network.NewNotFoundError("user does not exists", nil)
+	}
+
+	return s.GetUserPrivateProfile(user)
+}
+
+// soft delete, the user is kept with the status false
+func (s *service) DeactivateUser(userId primitive.ObjectID) error {
+	filter := bson.M{"_id": userId, "status": true}
+	update := bson.M{"$set": bson.M{"status": false, "updatedAt": time.Now()}}
+	result, err := s.userQueryBuilder.SingleQuery().UpdateOne(filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return network.NewNotFoundError("user does not exists", nil)
+	}
+	return nil
+}
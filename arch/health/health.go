@@ -0,0 +1,137 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultTimeout = 2 * time.Second
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// a check should return an error when the dependency can not serve the requests
+type Check = func(ctx context.Context) error
+
+type CheckResult struct {
+	Status    Status `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+func (r *Report) IsUp() bool {
+	return r.Status == StatusUp
+}
+
+type Registry interface {
+	Register(name string, check Check)
+	SetTimeout(timeout time.Duration)
+	// marks the service as not ready, used when the server starts draining
+	Drain()
+	Live() *Report
+	Ready(ctx context.Context) *Report
+}
+
+type registry struct {
+	mutex    sync.RWMutex
+	checks   map[string]Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewRegistry() Registry {
+	return &registry{
+		checks:  make(map[string]Check),
+		timeout: defaultTimeout,
+	}
+}
+
+func (r *registry) Register(name string, check Check) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.checks[name] = check
+}
+
+func (r *registry) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		r.timeout = timeout
+	}
+}
+
+func (r *registry) Drain() {
+	r.draining.Store(true)
+}
+
+// the process is alive as long as it can serve this, dependencies are not checked
+func (r *registry) Live() *Report {
+	return &Report{Status: StatusUp}
+}
+
+// runs all the checks concurrently, each bounded by the timeout
+func (r *registry) Ready(ctx context.Context) *Report {
+	r.mutex.RLock()
+	checks := make(map[string]Check, len(r.checks))
+	for name, check := range r.checks {
+		checks[name] = check
+	}
+	r.mutex.RUnlock()
+
+	report := Report{
+		Status: StatusUp,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			result := r.run(ctx, check)
+			mutex.Lock()
+			report.Checks[name] = result
+			mutex.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	if r.draining.Load() {
+		report.Status = StatusDown
+	}
+
+	return &report
+}
+
+func (r *registry) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
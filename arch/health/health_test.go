@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Ready_AllUp(t *testing.T) {
+	r := NewRegistry()
+	r.Register("mongo", func(ctx context.Context) error { return nil })
+	r.Register("redis", func(ctx context.Context) error { return nil })
+
+	report := r.Ready(context.Background())
+
+	assert.True(t, report.IsUp())
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, StatusUp, report.Checks["mongo"].Status)
+}
+
+func TestRegistry_Ready_CheckFailed(t *testing.T) {
+	r := NewRegistry()
+	r.Register("mongo", func(ctx context.Context) error { return nil })
+	r.Register("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	report := r.Ready(context.Background())
+
+	assert.False(t, report.IsUp())
+	assert.Equal(t, StatusUp, report.Checks["mongo"].Status)
+	assert.Equal(t, StatusDown, report.Checks["redis"].Status)
+	assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+}
+
+func TestRegistry_Ready_Timeout(t *testing.T) {
+	r := NewRegistry()
+	r.SetTimeout(10 * time.Millisecond)
+	r.Register("nats", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := r.Ready(context.Background())
+
+	assert.False(t, report.IsUp())
+	assert.Equal(t, StatusDown, report.Checks["nats"].Status)
+}
+
+func TestRegistry_Drain(t *testing.T) {
+	r := NewRegistry()
+	r.Register("mongo", func(ctx context.Context) error { return nil })
+
+	r.Drain()
+
+	assert.False(t, r.Ready(context.Background()).IsUp())
+	assert.True(t, r.Live().IsUp())
+}
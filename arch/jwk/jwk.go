@@ -0,0 +1,195 @@
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+// the path where the key set is published
+const WellKnownPath = "/.well-known/jwks.json"
+
+var ErrUnsupportedKey = errors.New("jwk: unsupported key type")
+var ErrKeyNotFound = errors.New("jwk: key not found")
+
+// public json web key, rfc 7517
+type Key struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid"`
+	// rsa
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// ec and okp
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type Set struct {
+	Keys []Key `json:"keys"`
+}
+
+func NewKey(public crypto.PublicKey, alg string) (*Key, error) {
+	key, err := publicMembers(public)
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := Thumbprint(public)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Use = "sig"
+	key.Alg = alg
+	key.Kid = kid
+	return key, nil
+}
+
+// the key id is derived from the key itself, rfc 7638
+// so that every service computes the same id for a key
+func Thumbprint(public crypto.PublicKey) (string, error) {
+	key, err := publicMembers(public)
+	if err != nil {
+		return "", err
+	}
+
+	// only the required members, in the lexicographic order
+	var members any
+	switch key.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{key.E, key.Kty, key.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{key.Crv, key.Kty, key.X, key.Y}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{key.Crv, key.Kty, key.X}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return encode(sum[:]), nil
+}
+
+func publicMembers(public crypto.PublicKey) (*Key, error) {
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		return &Key{
+			Kty: "RSA",
+			N:   encode(pub.N.Bytes()),
+			E:   encode(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, ErrUnsupportedKey
+		}
+		// the coordinates are padded to the size of the curve
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		return &Key{
+			Kty: "EC",
+			Crv: pub.Curve.Params().Name,
+			X:   encode(pub.X.FillBytes(make([]byte, size))),
+			Y:   encode(pub.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PublicKey:
+		return &Key{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   encode(pub),
+		}, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// useful for the consumers verifying the tokens with a fetched key set
+func (s *Set) PublicKey(kid string) (crypto.PublicKey, error) {
+	for _, key := range s.Keys {
+		if key.Kid == kid {
+			return key.PublicKey()
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+func (k *Key) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		if k.Crv != elliptic.P256().Params().Name {
+			return nil, ErrUnsupportedKey
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, ErrUnsupportedKey
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decode(data string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(data)
+}
@@ -0,0 +1,134 @@
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return key
+}
+
+func TestThumbprint_RFC7638(t *testing.T) {
+	// example key of the rfc 7638, section 3.1
+	key := Key{
+		Kty: "RSA",
+		E:   "AQAB",
+		N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECP" +
+			"ebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY" +
+			"368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0f" +
+			"M4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+	}
+
+	public, err := key.PublicKey()
+	assert.NoError(t, err)
+
+	kid, err := Thumbprint(public)
+	assert.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", kid)
+}
+
+func TestSet_PublicKey(t *testing.T) {
+	private := generateKey(t)
+
+	key, err := NewKey(&private.PublicKey, "RS256")
+	assert.NoError(t, err)
+
+	// the set survives the json round trip of the consumers
+	data, err := json.Marshal(&Set{Keys: []Key{*key}})
+	assert.NoError(t, err)
+
+	var set Set
+	assert.NoError(t, json.Unmarshal(data, &set))
+
+	public, err := set.PublicKey(key.Kid)
+	assert.NoError(t, err)
+	assert.True(t, private.PublicKey.Equal(public))
+
+	_, err = set.PublicKey("unknown")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestKeyRing_VerificationKey(t *testing.T) {
+	active := generateKey(t)
+	retired := generateKey(t)
+
+	ring, err := NewKeyRing("RS256", active, &retired.PublicKey, &active.PublicKey)
+	assert.NoError(t, err)
+
+	kid, signing := ring.SigningKey()
+	assert.Equal(t, active, signing)
+	assert.Len(t, ring.Set().Keys, 2)
+
+	public, err := ring.VerificationKey(kid)
+	assert.NoError(t, err)
+	assert.True(t, active.PublicKey.Equal(public))
+
+	retiredKid, _ := Thumbprint(&retired.PublicKey)
+	public, err = ring.VerificationKey(retiredKid)
+	assert.NoError(t, err)
+	assert.True(t, retired.PublicKey.Equal(public))
+
+	public, err = ring.VerificationKey("")
+	assert.NoError(t, err)
+	assert.True(t, active.PublicKey.Equal(public))
+
+	_, err = ring.VerificationKey("unknown")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestThumbprint_RFC8037(t *testing.T) {
+	// example key of the rfc 8037, appendix a.3
+	key := Key{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+
+	public, err := key.PublicKey()
+	assert.NoError(t, err)
+
+	kid, err := Thumbprint(public)
+	assert.NoError(t, err)
+	assert.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", kid)
+}
+
+func TestNewKey_RoundTrip(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		alg    string
+		public crypto.PublicKey
+	}{
+		{"RS256", &generateKey(t).PublicKey},
+		{"ES256", &ecKey.PublicKey},
+		{"EdDSA", edPublic},
+	}
+
+	for _, tt := range tests {
+		key, err := NewKey(tt.public, tt.alg)
+		assert.NoError(t, err, tt.alg)
+		assert.Equal(t, tt.alg, key.Alg)
+
+		public, err := key.PublicKey()
+		assert.NoError(t, err, tt.alg)
+		assert.True(t, public.(interface{ Equal(crypto.PublicKey) bool }).Equal(tt.public), tt.alg)
+	}
+}
+
+func TestNewKey_UnsupportedCurve(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	_, err = NewKey(&key.PublicKey, "ES384")
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+}
@@ -0,0 +1,75 @@
+package jwk
+
+import (
+	"crypto"
+)
+
+// one active key signs the tokens, the retired keys are kept to verify
+// the tokens they signed until those expire
+type KeyRing interface {
+	SigningKey() (string, crypto.PrivateKey)
+	VerificationKey(kid string) (crypto.PublicKey, error)
+	Set() *Set
+}
+
+type keyRing struct {
+	kid     string
+	signing crypto.PrivateKey
+	keys    map[string]crypto.PublicKey
+	set     *Set
+}
+
+// all the keys are of the alg, the tokens of an other alg are not verified
+func NewKeyRing(alg string, signing crypto.Signer, retired ...crypto.PublicKey) (KeyRing, error) {
+	ring := &keyRing{
+		signing: signing,
+		keys:    make(map[string]crypto.PublicKey),
+		set:     &Set{Keys: []Key{}},
+	}
+
+	kid, err := ring.add(alg, signing.Public())
+	if err != nil {
+		return nil, err
+	}
+	ring.kid = kid
+
+	for _, public := range retired {
+		if _, err := ring.add(alg, public); err != nil {
+			return nil, err
+		}
+	}
+
+	return ring, nil
+}
+
+func (r *keyRing) add(alg string, public crypto.PublicKey) (string, error) {
+	key, err := NewKey(public, alg)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := r.keys[key.Kid]; !ok {
+		r.keys[key.Kid] = public
+		r.set.Keys = append(r.set.Keys, *key)
+	}
+	return key.Kid, nil
+}
+
+func (r *keyRing) SigningKey() (string, crypto.PrivateKey) {
+	return r.kid, r.signing
+}
+
+// the tokens signed before the key ids were added are verified with the active key
+func (r *keyRing) VerificationKey(kid string) (crypto.PublicKey, error) {
+	if kid == "" {
+		return r.keys[r.kid], nil
+	}
+	public, ok := r.keys[kid]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return public, nil
+}
+
+func (r *keyRing) Set() *Set {
+	return r.set
+}
@@ -0,0 +1,104 @@
+package lockout
+
+import (
+	"context"
+	"time"
+)
+
+type Policy struct {
+	// failures allowed before the delays start
+	FreeAttempts int
+	// the delay doubles with every failure after the free attempts, up to the max delay
+	// both are needed for the delays
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// failures that lock the key, 0 disables the lockout
+	Threshold int
+	// failures are forgotten after the window since the last one, it is the lockout duration as well
+	Window time.Duration
+}
+
+type Status struct {
+	Failures int
+	Locked   bool
+	// wait before the next attempt is allowed, 0 when it is allowed now
+	RetryAfter time.Duration
+}
+
+func (s *Status) Allowed() bool {
+	return s.RetryAfter <= 0
+}
+
+// failed attempts of a key, the window restarts with every failure
+type Counter interface {
+	// returns the failures including the added one
+	Add(ctx context.Context, key string, window time.Duration) (int, error)
+	// returns the failures and the time since the last one
+	Get(ctx context.Context, key string, window time.Duration) (int, time.Duration, error)
+	Reset(ctx context.Context, keys ...string) error
+}
+
+type Guard interface {
+	Check(ctx context.Context, key string) (*Status, error)
+	Fail(ctx context.Context, key string) (*Status, error)
+	Reset(ctx context.Context, key string) error
+}
+
+type guard struct {
+	counter Counter
+	policy  Policy
+}
+
+func NewGuard(counter Counter, policy Policy) Guard {
+	return &guard{
+		counter: counter,
+		policy:  policy,
+	}
+}
+
+func (g *guard) Check(ctx context.Context, key string) (*Status, error) {
+	failures, elapsed, err := g.counter.Get(ctx, key, g.policy.Window)
+	if err != nil {
+		return nil, err
+	}
+	return g.status(failures, elapsed), nil
+}
+
+func (g *guard) Fail(ctx context.Context, key string) (*Status, error) {
+	failures, err := g.counter.Add(ctx, key, g.policy.Window)
+	if err != nil {
+		return nil, err
+	}
+	return g.status(failures, 0), nil
+}
+
+func (g *guard) Reset(ctx context.Context, key string) error {
+	return g.counter.Reset(ctx, key)
+}
+
+func (g *guard) status(failures int, elapsed time.Duration) *Status {
+	status := Status{Failures: failures}
+
+	if g.policy.Threshold > 0 && failures >= g.policy.Threshold {
+		status.Locked = true
+		status.RetryAfter = max(g.policy.Window-elapsed, 0)
+		return &status
+	}
+
+	status.RetryAfter = max(g.policy.Delay(failures)-elapsed, 0)
+	return &status
+}
+
+// delay before the next attempt after the failures
+func (p Policy) Delay(failures int) time.Duration {
+	n := failures - p.FreeAttempts
+	if n <= 0 || p.BaseDelay <= 0 || p.MaxDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < n && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
@@ -0,0 +1,127 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Delay(t *testing.T) {
+	p := Policy{FreeAttempts: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	assert.Equal(t, time.Duration(0), p.Delay(3))
+	assert.Equal(t, time.Second, p.Delay(4))
+	assert.Equal(t, 2*time.Second, p.Delay(5))
+	assert.Equal(t, 8*time.Second, p.Delay(7))
+	assert.Equal(t, 10*time.Second, p.Delay(8))
+	assert.Equal(t, 10*time.Second, p.Delay(1000))
+
+	assert.Equal(t, time.Duration(0), Policy{FreeAttempts: 3}.Delay(10))
+}
+
+func TestGuard_ProgressiveDelay(t *testing.T) {
+	now := time.Now()
+	counter := NewMemoryCounter().(*memoryCounter)
+	counter.now = func() time.Time { return now }
+
+	g := NewGuard(counter, Policy{
+		FreeAttempts: 2,
+		BaseDelay:    time.Second,
+		MaxDelay:     4 * time.Second,
+		Threshold:    10,
+		Window:       time.Hour,
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		status, err := g.Fail(ctx, "key")
+		assert.NoError(t, err)
+		assert.True(t, status.Allowed())
+	}
+
+	status, _ := g.Fail(ctx, "key")
+	assert.False(t, status.Allowed())
+	assert.Equal(t, time.Second, status.RetryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+	status, _ = g.Check(ctx, "key")
+	assert.False(t, status.Allowed())
+	assert.Equal(t, 500*time.Millisecond, status.RetryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+	status, _ = g.Check(ctx, "key")
+	assert.True(t, status.Allowed())
+
+	status, _ = g.Fail(ctx, "key")
+	assert.Equal(t, 2*time.Second, status.RetryAfter)
+	assert.Equal(t, 4, status.Failures)
+}
+
+func TestGuard_Lockout(t *testing.T) {
+	now := time.Now()
+	counter := NewMemoryCounter().(*memoryCounter)
+	counter.now = func() time.Time { return now }
+
+	g := NewGuard(counter, Policy{Threshold: 3, Window: 15 * time.Minute})
+	ctx := context.Background()
+
+	g.Fail(ctx, "key")
+	g.Fail(ctx, "key")
+	status, _ := g.Fail(ctx, "key")
+	assert.True(t, status.Locked)
+	assert.Equal(t, 15*time.Minute, status.RetryAfter)
+
+	// other keys are not affected
+	status, _ = g.Check(ctx, "other")
+	assert.True(t, status.Allowed())
+	assert.Equal(t, 0, status.Failures)
+
+	now = now.Add(10 * time.Minute)
+	status, _ = g.Check(ctx, "key")
+	assert.True(t, status.Locked)
+	assert.Equal(t, 5*time.Minute, status.RetryAfter)
+
+	now = now.Add(5 * time.Minute)
+	status, _ = g.Check(ctx, "key")
+	assert.False(t, status.Locked)
+	assert.True(t, status.Allowed())
+	assert.Equal(t, 0, status.Failures)
+}
+
+func TestGuard_Reset(t *testing.T) {
+	g := NewGuard(NewMemoryCounter(), Policy{Threshold: 1, Window: time.Hour})
+	ctx := context.Background()
+
+	status, _ := g.Fail(ctx, "key")
+	assert.True(t, status.Locked)
+
+	assert.NoError(t, g.Reset(ctx, "key"))
+
+	status, _ = g.Check(ctx, "key")
+	assert.False(t, status.Locked)
+	assert.Equal(t, 0, status.Failures)
+}
+
+func TestMemoryCounter_WindowRestarts(t *testing.T) {
+	now := time.Now()
+	counter := NewMemoryCounter().(*memoryCounter)
+	counter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	counter.Add(ctx, "key", time.Minute)
+	now = now.Add(50 * time.Second)
+	counter.Add(ctx, "key", time.Minute)
+
+	// the second failure keeps the first one
+	now = now.Add(50 * time.Second)
+	failures, elapsed, err := counter.Get(ctx, "key", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, failures)
+	assert.Equal(t, 50*time.Second, elapsed)
+
+	now = now.Add(10 * time.Second)
+	failures, _, _ = counter.Get(ctx, "key", time.Minute)
+	assert.Equal(t, 0, failures)
+}
@@ -0,0 +1,78 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	failures int
+	last     time.Time
+	window   time.Duration
+}
+
+type memoryCounter struct {
+	mutex   sync.Mutex
+	entries map[string]*entry
+	now     func() time.Time
+}
+
+// counters live in the process, so it is meant for the tests and the single instance setups
+func NewMemoryCounter() Counter {
+	return &memoryCounter{
+		entries: make(map[string]*entry),
+		now:     time.Now,
+	}
+}
+
+func (c *memoryCounter) Add(ctx context.Context, key string, window time.Duration) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	e := c.entry(key, now)
+	if e == nil {
+		e = &entry{}
+		c.entries[key] = e
+	}
+	e.failures++
+	e.last = now
+	e.window = window
+	return e.failures, nil
+}
+
+func (c *memoryCounter) Get(ctx context.Context, key string, window time.Duration) (int, time.Duration, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	e := c.entry(key, now)
+	if e == nil {
+		return 0, 0, nil
+	}
+	return e.failures, now.Sub(e.last), nil
+}
+
+func (c *memoryCounter) Reset(ctx context.Context, keys ...string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// the expired entry is removed
+func (c *memoryCounter) entry(key string, now time.Time) *entry {
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if !now.Before(e.last.Add(e.window)) {
+		delete(c.entries, key)
+		return nil
+	}
+	return e
+}
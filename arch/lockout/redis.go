@@ -0,0 +1,71 @@
+package lockout
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/unusualcodeorg/goserve/arch/redis"
+)
+
+const keyPrefix = "lockout:"
+
+type redisCounter struct {
+	store redis.Store
+}
+
+// counters are shared by all the instances connected to the store
+func NewRedisCounter(store redis.Store) Counter {
+	return &redisCounter{
+		store: store,
+	}
+}
+
+func (c *redisCounter) Add(ctx context.Context, key string, window time.Duration) (int, error) {
+	var incr *goredis.IntCmd
+	_, err := c.store.GetInstance().TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		incr = pipe.Incr(ctx, keyPrefix+key)
+		pipe.PExpire(ctx, keyPrefix+key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
+
+// the ttl is reset with every failure, so the time since the last one is the window minus the ttl
+func (c *redisCounter) Get(ctx context.Context, key string, window time.Duration) (int, time.Duration, error) {
+	var get *goredis.StringCmd
+	var ttl *goredis.DurationCmd
+	_, err := c.store.GetInstance().Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		get = pipe.Get(ctx, keyPrefix+key)
+		ttl = pipe.PTTL(ctx, keyPrefix+key)
+		return nil
+	})
+	if err != nil && !redis.IsNil(err) {
+		return 0, 0, err
+	}
+
+	failures, err := get.Int()
+	if redis.IsNil(err) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	elapsed := window - ttl.Val()
+	if ttl.Val() < 0 || elapsed < 0 {
+		elapsed = 0
+	}
+	return failures, elapsed, nil
+}
+
+func (c *redisCounter) Reset(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = keyPrefix + key
+	}
+	return c.store.GetInstance().Del(ctx, prefixed...).Err()
+}
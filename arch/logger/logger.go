@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+type Config struct {
+	Format string
+	Level  string
+	Output io.Writer
+}
+
+func NewLogger(config *Config) *slog.Logger {
+	output := config.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	opts := &slog.HandlerOptions{Level: parseLevel(config.Level)}
+
+	var handler slog.Handler
+	switch strings.ToLower(config.Format) {
+	case FormatText:
+		handler = slog.NewTextHandler(output, opts)
+	default:
+		handler = slog.NewJSONHandler(output, opts)
+	}
+
+	return slog.New(handler)
+}
+
+// useful in tests where the log output is not required
+func NewDiscardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func parseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
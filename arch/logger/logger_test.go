@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogger_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(&Config{Format: FormatJSON, Level: "info", Output: &buf})
+
+	l.Info("connected", "service", "mongo")
+
+	var entry map[string]any
+	err := json.Unmarshal(buf.Bytes(), &entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "connected", entry["msg"])
+	assert.Equal(t, "mongo", entry["service"])
+}
+
+func TestNewLogger_Text(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(&Config{Format: FormatText, Level: "info", Output: &buf})
+
+	l.Info("connected", "service", "redis")
+
+	assert.Contains(t, buf.String(), "msg=connected")
+	assert.Contains(t, buf.String(), "service=redis")
+}
+
+func TestNewLogger_Level(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(&Config{Format: FormatJSON, Level: "warn", Output: &buf})
+
+	l.Info("hidden")
+	assert.Empty(t, buf.String())
+
+	l.Warn("shown")
+	assert.Contains(t, buf.String(), "shown")
+}
+
+func TestParseLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, parseLevel("debug"))
+	assert.Equal(t, slog.LevelError, parseLevel("ERROR"))
+	assert.Equal(t, slog.LevelInfo, parseLevel("invalid"))
+	assert.Equal(t, slog.LevelInfo, parseLevel(""))
+}
@@ -0,0 +1,48 @@
+package lru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Eviction(t *testing.T) {
+	c := NewCache[string, int](2, time.Minute)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestCache_Expiry(t *testing.T) {
+	now := time.Now()
+	c := NewCache[string, int](2, time.Minute).(*cache[string, int])
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	now = now.Add(2 * time.Minute)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestCache_Delete(t *testing.T) {
+	c := NewCache[string, int](2, time.Minute)
+
+	c.Set("a", 1)
+	c.Delete("a")
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// writes every mail as an eml file of the dir, useful in development
+func NewFileMailer(dir string, from string) Mailer {
+	return &fileMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (m *fileMailer) Send(ctx context.Context, mail *Mail) error {
+	now := time.Now()
+	msg, err := mail.Message(m.from, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d.eml", now.UnixNano())
+	return os.WriteFile(filepath.Join(m.dir, name), msg, 0o600)
+}
@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+const (
+	TypeSMTP   = "smtp"
+	TypeFile   = "file"
+	TypeMemory = "memory"
+)
+
+var ErrInvalidHeader = errors.New("mailer: header contains a line break")
+
+type Config struct {
+	Type    string
+	From    string
+	FileDir string
+	// smtp
+	Host string
+	Port uint16
+	User string
+	Pwd  string
+}
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, mail *Mail) error
+}
+
+func NewMailer(config *Config) (Mailer, error) {
+	switch strings.ToLower(config.Type) {
+	case TypeSMTP:
+		return NewSMTPMailer(config), nil
+	case TypeFile:
+		return NewFileMailer(config.FileDir, config.From), nil
+	case "", TypeMemory:
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer: %s", config.Type)
+	}
+}
+
+// plain text message of the rfc 5322
+func (mail *Mail) Message(from string, date time.Time) ([]byte, error) {
+	for _, header := range []string{from, mail.To, mail.Subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mail.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(mail.Body, "\n", "\r\n"))
+	return []byte(b.String()), nil
+}
+
+// the bare address of a "name <address>" header
+func envelopeAddress(header string) (string, error) {
+	address, err := mail.ParseAddress(header)
+	if err != nil {
+		return "", err
+	}
+	return address.Address, nil
+}
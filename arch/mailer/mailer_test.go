@@ -0,0 +1,86 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMail_Message(t *testing.T) {
+	mail := &Mail{To: "user@example.com", Subject: "Hello", Body: "line 1\nline 2"}
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	msg, err := mail.Message("goserve <no-reply@example.com>", date)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "From: goserve <no-reply@example.com>\r\n"+
+		"To: user@example.com\r\n"+
+		"Subject: Hello\r\n"+
+		"Date: Tue, 02 Jan 2024 03:04:05 +0000\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=UTF-8\r\n"+
+		"\r\n"+
+		"line 1\r\nline 2", string(msg))
+}
+
+func TestMail_MessageHeaderInjection(t *testing.T) {
+	mail := &Mail{To: "user@example.com\r\nBcc: other@example.com", Subject: "Hello", Body: "body"}
+
+	_, err := mail.Message("no-reply@example.com", time.Now())
+
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+}
+
+func TestNewMailer(t *testing.T) {
+	m, err := NewMailer(&Config{Type: TypeMemory})
+	assert.NoError(t, err)
+	assert.Implements(t, (*MemoryMailer)(nil), m)
+
+	m, err = NewMailer(&Config{Type: "SMTP", Host: "localhost", Port: 25})
+	assert.NoError(t, err)
+	assert.NotNil(t, m)
+
+	_, err = NewMailer(&Config{Type: "pigeon"})
+	assert.EqualError(t, err, "unknown mailer: pigeon")
+}
+
+func TestMemoryMailer(t *testing.T) {
+	m := NewMemoryMailer()
+	mail := &Mail{To: "user@example.com", Subject: "Hello", Body: "body"}
+
+	assert.NoError(t, m.Send(context.Background(), mail))
+	assert.Error(t, m.Send(context.Background(), &Mail{To: "a\nb"}))
+
+	assert.Equal(t, []*Mail{mail}, m.Sent())
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mails")
+	m := NewFileMailer(dir, "no-reply@example.com")
+
+	err := m.Send(context.Background(), &Mail{To: "user@example.com", Subject: "Hello", Body: "body"})
+	assert.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "To: user@example.com\r\n")
+	assert.Contains(t, string(content), "\r\n\r\nbody")
+}
+
+func TestSMTPMailer_CanceledContext(t *testing.T) {
+	m := NewSMTPMailer(&Config{Host: "localhost", Port: 25, From: "no-reply@example.com"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := m.Send(ctx, &Mail{To: "user@example.com", Subject: "Hello", Body: "body"})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// keeps the sent mails, useful in tests
+type MemoryMailer interface {
+	Mailer
+	Sent() []*Mail
+}
+
+type memoryMailer struct {
+	mutex sync.Mutex
+	sent  []*Mail
+}
+
+func NewMemoryMailer() MemoryMailer {
+	return &memoryMailer{}
+}
+
+func (m *memoryMailer) Send(ctx context.Context, mail *Mail) error {
+	if _, err := mail.Message("", time.Time{}); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.sent = append(m.sent, mail)
+	return nil
+}
+
+func (m *memoryMailer) Sent() []*Mail {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]*Mail(nil), m.sent...)
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"time"
+)
+
+type smtpMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// the auth is skipped when the user is empty, e.g. for a local relay
+func NewSMTPMailer(config *Config) Mailer {
+	var auth smtp.Auth
+	if config.User != "" {
+		auth = smtp.PlainAuth("", config.User, config.Pwd, config.Host)
+	}
+	return &smtpMailer{
+		addr: fmt.Sprintf("%s:%d", config.Host, config.Port),
+		from: config.From,
+		auth: auth,
+	}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, mail *Mail) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	msg, err := mail.Message(m.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	from, err := envelopeAddress(m.from)
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.addr, m.auth, from, []string{mail.To}, msg)
+}
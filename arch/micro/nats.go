@@ -1,11 +1,14 @@
 package micro
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"log/slog"
 	"time"
 
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/micro"
+	"github.com/unusualcodeorg/goserve/arch/health"
 )
 
 type Config struct {
@@ -18,12 +21,14 @@ type Config struct {
 type NatsClient interface {
 	GetInstance() *natsClient
 	Disconnect()
+	RegisterHealthCheck(registry health.Registry)
 }
 
 type natsClient struct {
 	Conn    *nats.Conn
 	Service micro.Service
 	Timeout time.Duration
+	logger  *slog.Logger
 }
 
 func (n *natsClient) GetInstance() *natsClient {
@@ -31,16 +36,28 @@ func (n *natsClient) GetInstance() *natsClient {
 }
 
 func (n *natsClient) Disconnect() {
-	fmt.Println("disconnecting nats..")
+	n.logger.Info("disconnecting nats")
 	n.Conn.Close()
-	fmt.Println("disconnected nats")
+	n.logger.Info("disconnected nats")
 }
 
-func NewNatsClient(config *Config) NatsClient {
-	fmt.Println("connecting to nats..")
+// a flush round trips to the server, so it also detects a stale connection
+func (n *natsClient) RegisterHealthCheck(registry health.Registry) {
+	registry.Register("nats", func(ctx context.Context) error {
+		if !n.Conn.IsConnected() {
+			return errors.New("nats is not connected: " + n.Conn.Status().String())
+		}
+		return n.Conn.FlushWithContext(ctx)
+	})
+}
+
+func NewNatsClient(config *Config, logger *slog.Logger) NatsClient {
+	logger = logger.With("component", "nats")
+	logger.Info("connecting to nats")
 
 	nc, err := nats.Connect(config.NatsUrl)
 	if err != nil {
+		logger.Error("connection to nats failed", "error", err)
 		panic(err)
 	}
 
@@ -49,14 +66,16 @@ func NewNatsClient(config *Config) NatsClient {
 		Version: config.NatsServiceVersion,
 	})
 	if err != nil {
+		logger.Error("adding nats service failed", "error", err)
 		panic(err)
 	}
 
-	fmt.Println("connected to nats")
+	logger.Info("connected to nats")
 
 	return &natsClient{
 		Conn:    nc,
 		Service: srv,
 		Timeout: config.Timeout,
+		logger:  logger,
 	}
 }
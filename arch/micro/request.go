@@ -1,9 +1,37 @@
 package micro
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"net/http"
 	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/unusualcodeorg/goserve/arch/micro"
+
+var (
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "nats_request_duration_seconds",
+		Help:    "Duration of the nats requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"subject"})
+
+	requestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "nats_request_errors_total",
+		Help: "Count of the failed nats requests.",
+	}, []string{"subject"})
 )
 
 type RequestBuilder[T any] interface {
@@ -29,35 +57,69 @@ func (c *requestBuilder[T]) NatsClient() NatsClient {
 	return c.natsClient
 }
 
-
 func (c *requestBuilder[T]) Request(data any) Request[T] {
 	return newRequest(c, data)
 }
 
 type Request[T any] interface {
+	WithContext(ctx context.Context) Request[T]
 	Nats() (*T, error)
 }
 
 type request[T any] struct {
 	builder *requestBuilder[T]
-	data       any
+	data    any
+	context context.Context
 }
 
 func newRequest[T any](builder *requestBuilder[T], data any) Request[T] {
 	return &request[T]{
-		builder:    builder,
-		data: data,
+		builder: builder,
+		data:    data,
+		context: context.Background(),
 	}
 }
 
-func (r *request[T]) Nats() (*T, error) {
+// the request id and the trace found in the context are forwarded in the nats headers
+func (r *request[T]) WithContext(ctx context.Context) Request[T] {
+	r.context = ctx
+	return r
+}
+
+func (r *request[T]) Nats() (data *T, err error) {
+	ctx, span := otel.Tracer(tracerName).Start(r.context, "nats.request "+r.builder.subject,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("nats"),
+			semconv.MessagingDestinationName(r.builder.subject),
+		),
+	)
+	start := time.Now()
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			requestErrors.WithLabelValues(r.builder.subject).Inc()
+		}
+		span.End()
+		requestDuration.WithLabelValues(r.builder.subject).Observe(time.Since(start).Seconds())
+	}()
+
 	sendMsg := NewMessage(r.data, nil)
 	sendPayload, err := json.Marshal(sendMsg)
 	if err != nil {
 		return nil, err
 	}
 
-	msg, err := r.builder.natsClient.GetInstance().Conn.Request(r.builder.subject, sendPayload, r.builder.timeout)
+	natsMsg := nats.NewMsg(r.builder.subject)
+	natsMsg.Data = sendPayload
+	if id := network.RequestIdFromContext(r.context); id != "" {
+		natsMsg.Header.Set(network.RequestIdHeader, id)
+		span.SetAttributes(attribute.String("request.id", id))
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(http.Header(natsMsg.Header)))
+
+	msg, err := r.builder.natsClient.GetInstance().Conn.RequestMsg(natsMsg, r.builder.timeout)
 	if err != nil {
 		return nil, err
 	}
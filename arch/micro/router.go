@@ -2,7 +2,9 @@ package micro
 
 import (
 	"fmt"
+	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -14,11 +16,13 @@ type router struct {
 	natsClient NatsClient
 }
 
-func NewRouter(mode string, natsClient NatsClient) Router {
-	return &router{
-		netRouter:  network.NewRouter(mode),
+func NewRouter(mode string, trustedProxies []string, natsClient NatsClient, logger *slog.Logger) Router {
+	r := router{
+		netRouter:  network.NewRouter(mode, trustedProxies, logger),
 		natsClient: natsClient,
 	}
+	r.netRouter.RegisterShutdownHook(natsClient.Disconnect)
+	return &r
 }
 
 func (r *router) GetEngine() *gin.Engine {
@@ -54,8 +58,24 @@ func (r *router) LoadControllers(controllers []Controller) {
 	}
 }
 
-func (r *router) Start(ip string, port uint16) {
-	r.netRouter.Start(ip, port)
+func (r *router) SetShutdownTimeout(timeout time.Duration) {
+	r.netRouter.SetShutdownTimeout(timeout)
+}
+
+func (r *router) RegisterShutdownHook(hook network.ShutdownHook) {
+	r.netRouter.RegisterShutdownHook(hook)
+}
+
+func (r *router) RegisterDrainHook(hook network.ShutdownHook) {
+	r.netRouter.RegisterDrainHook(hook)
+}
+
+func (r *router) Shutdown() {
+	r.netRouter.Shutdown()
+}
+
+func (r *router) Start(ip string, port uint16, options ...network.ServerOption) {
+	r.netRouter.Start(ip, port, options...)
 }
 
 func (r *router) RegisterValidationParsers(tagNameFunc validator.TagNameFunc) {
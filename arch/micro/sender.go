@@ -1,10 +1,15 @@
 package micro
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
 
+	"github.com/nats-io/nats.go/micro"
 	"github.com/unusualcodeorg/goserve/arch/network"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 type sender struct{}
@@ -16,22 +21,52 @@ func NewMessageSender() MessageSender {
 func (m *sender) SendNats(req NatsRequest) SendMessage {
 	return &send{
 		natsRequest: req,
+		requestId:   NatsRequestId(req),
 	}
 }
 
 type send struct {
 	natsRequest NatsRequest
+	requestId   string
 }
 
 func (s *send) Message(data any) {
-	s.natsRequest.RespondJSON(NewAnyMessage(data, nil))
+	s.natsRequest.RespondJSON(NewAnyMessage(data, nil), s.headers())
 }
 
 func (s *send) Error(err error) {
 	if apiError, ok := err.(network.ApiError); ok {
 		msg := fmt.Sprintf("%d:%s", apiError.GetCode(), apiError.GetMessage())
-		s.natsRequest.RespondJSON(NewAnyMessage(nil, errors.New(msg)))
+		s.natsRequest.RespondJSON(NewAnyMessage(nil, errors.New(msg)), s.headers())
 		return
 	}
-	s.natsRequest.RespondJSON(NewAnyMessage(nil, err))
+	s.natsRequest.RespondJSON(NewAnyMessage(nil, err), s.headers())
+}
+
+func (s *send) headers() micro.RespondOpt {
+	headers := micro.Headers{}
+	if s.requestId != "" {
+		headers[network.RequestIdHeader] = []string{s.requestId}
+	}
+	return micro.WithHeaders(headers)
+}
+
+func NatsRequestId(req NatsRequest) string {
+	id := req.Headers().Get(network.RequestIdHeader)
+	if !network.IsValidRequestId(id) {
+		return ""
+	}
+	return id
+}
+
+// context to be used for the downstream calls made while handling a nats request
+// it carries the caller's request id and trace
+func NatsRequestContext(req NatsRequest) context.Context {
+	id := NatsRequestId(req)
+	if id == "" {
+		id = network.NewRequestId()
+	}
+	ctx := network.ContextWithRequestId(context.Background(), id)
+	headers := propagation.HeaderCarrier(http.Header(req.Headers()))
+	return otel.GetTextMapPropagator().Extract(ctx, headers)
 }
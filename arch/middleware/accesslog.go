@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// provides the request specific log attributes like the api key and the user
+type AccessLogAttrs = func(ctx *gin.Context) []slog.Attr
+
+type accessLog struct {
+	network.BaseMiddleware
+	logger *slog.Logger
+	attrs  AccessLogAttrs
+}
+
+func NewAccessLog(logger *slog.Logger, attrs AccessLogAttrs) network.RootMiddleware {
+	return &accessLog{
+		BaseMiddleware: network.NewBaseMiddleware(),
+		logger:         logger.With("component", "access"),
+		attrs:          attrs,
+	}
+}
+
+func (m *accessLog) Attach(engine *gin.Engine) {
+	engine.Use(m.Handler)
+}
+
+func (m *accessLog) Handler(ctx *gin.Context) {
+	start := time.Now()
+
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	attrs := []slog.Attr{
+		slog.String("method", ctx.Request.Method),
+		slog.String("route", ctx.FullPath()),
+		slog.Int("status", status),
+		slog.Duration("latency", time.Since(start)),
+	}
+	if id := network.GetRequestId(ctx); id != "" {
+		attrs = append(attrs, slog.String("requestId", id))
+	}
+	if spanCtx := trace.SpanContextFromContext(ctx.Request.Context()); spanCtx.HasTraceID() {
+		attrs = append(attrs, slog.String("traceId", spanCtx.TraceID().String()))
+	}
+	if m.attrs != nil {
+		attrs = append(attrs, m.attrs(ctx)...)
+	}
+
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	} else if status >= http.StatusBadRequest {
+		level = slog.LevelWarn
+	}
+
+	m.logger.LogAttrs(ctx.Request.Context(), level, "request", attrs...)
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/arch/logger"
+	"github.com/unusualcodeorg/goserve/arch/network"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.NewLogger(&logger.Config{Format: logger.FormatJSON, Level: "info", Output: &buf})
+
+	attrs := func(ctx *gin.Context) []slog.Attr {
+		return []slog.Attr{slog.String("userId", "user_id")}
+	}
+
+	rr := network.MockTestRootMiddlewareWithUrl(t, "/blog/id/:id", "/blog/id/123",
+		NewAccessLog(l, attrs),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var entry map[string]any
+	err := json.Unmarshal(buf.Bytes(), &entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "request", entry["msg"])
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/blog/id/:id", entry["route"])
+	assert.Equal(t, float64(http.StatusOK), entry["status"])
+	assert.Equal(t, "user_id", entry["userId"])
+	assert.Contains(t, entry, "latency")
+}
+
+func TestAccessLogMiddleware_ErrorLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.NewLogger(&logger.Config{Format: logger.FormatJSON, Level: "info", Output: &buf})
+
+	mockHandler := func(ctx *gin.Context) {
+		network.NewResponseSender().Send(ctx).NotFoundError("not found", nil)
+	}
+
+	rr := network.MockTestRootMiddleware(t, NewAccessLog(l, nil), mockHandler)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, buf.String(), `"level":"WARN"`)
+	assert.Contains(t, buf.String(), `"status":404`)
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/arch/health"
+	"github.com/unusualcodeorg/goserve/arch/network"
+)
+
+type healthCheck struct {
+	network.BaseMiddleware
+	registry health.Registry
+}
+
+// mounts the probes, it must be attached before the key protection since orchestrators do not send an api key
+func NewHealth(registry health.Registry) network.RootMiddleware {
+	return &healthCheck{
+		BaseMiddleware: network.NewBaseMiddleware(),
+		registry:       registry,
+	}
+}
+
+func (m *healthCheck) Attach(engine *gin.Engine) {
+	engine.GET("/health/live", m.live)
+	engine.GET("/health/ready", m.Handler)
+}
+
+// readiness probe
+func (m *healthCheck) Handler(ctx *gin.Context) {
+	report := m.registry.Ready(ctx.Request.Context())
+	m.send(ctx, report)
+}
+
+func (m *healthCheck) live(ctx *gin.Context) {
+	m.send(ctx, m.registry.Live())
+}
+
+func (m *healthCheck) send(ctx *gin.Context, report *health.Report) {
+	status := http.StatusOK
+	if !report.IsUp() {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.AbortWithStatusJSON(status, report)
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/arch/health"
+	"github.com/unusualcodeorg/goserve/arch/network"
+)
+
+func TestHealthMiddleware_Live(t *testing.T) {
+	registry := health.NewRegistry()
+	registry.Register("mongo", func(ctx context.Context) error { return errors.New("down") })
+
+	rr := network.MockTestRootMiddlewareWithUrl(t, "/blog/id/:id", "/health/live",
+		NewHealth(registry),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"up"`)
+}
+
+func TestHealthMiddleware_Ready(t *testing.T) {
+	registry := health.NewRegistry()
+	registry.Register("mongo", func(ctx context.Context) error { return nil })
+
+	rr := network.MockTestRootMiddlewareWithUrl(t, "/blog/id/:id", "/health/ready",
+		NewHealth(registry),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"mongo":{"status":"up"`)
+}
+
+func TestHealthMiddleware_Ready_Failing(t *testing.T) {
+	registry := health.NewRegistry()
+	registry.Register("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	rr := network.MockTestRootMiddlewareWithUrl(t, "/blog/id/:id", "/health/ready",
+		NewHealth(registry),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"error":"connection refused"`)
+}
+
+func TestHealthMiddleware_Ready_Draining(t *testing.T) {
+	registry := health.NewRegistry()
+	registry.Drain()
+
+	rr := network.MockTestRootMiddlewareWithUrl(t, "/blog/id/:id", "/health/ready",
+		NewHealth(registry),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
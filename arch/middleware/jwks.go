@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/arch/jwk"
+	"github.com/unusualcodeorg/goserve/arch/network"
+)
+
+type jwks struct {
+	network.BaseMiddleware
+	set *jwk.Set
+}
+
+// publishes the token verification keys, it must be attached before the key protection
+// since the other services fetch the keys without an api key
+func NewJWKS(set *jwk.Set) network.RootMiddleware {
+	return &jwks{
+		BaseMiddleware: network.NewBaseMiddleware(),
+		set:            set,
+	}
+}
+
+func (m *jwks) Attach(engine *gin.Engine) {
+	engine.GET(jwk.WellKnownPath, m.Handler)
+}
+
+func (m *jwks) Handler(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.AbortWithStatusJSON(http.StatusOK, m.set)
+}
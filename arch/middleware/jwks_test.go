@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/arch/jwk"
+	"github.com/unusualcodeorg/goserve/arch/network"
+)
+
+func TestJWKSMiddleware(t *testing.T) {
+	set := &jwk.Set{Keys: []jwk.Key{{Kty: "RSA", Kid: "kid", N: "n", E: "AQAB"}}}
+
+	rr := network.MockTestRootMiddlewareWithUrl(t, "/blog/id/:id", jwk.WellKnownPath,
+		NewJWKS(set),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "public, max-age=300", rr.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"keys":[{"kty":"RSA","kid":"kid","n":"n","e":"AQAB"}]}`, rr.Body.String())
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/unusualcodeorg/goserve/arch/network"
+)
+
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Count of the http requests.",
+	}, []string{"method", "route", "status", "code"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of the http requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+)
+
+type metrics struct {
+	network.BaseMiddleware
+	path  string
+	token string
+}
+
+// the metrics endpoint is mounted only when a token is provided
+// it must be attached before the key protection since scrapers do not send an api key
+func NewMetrics(path string, token string) network.RootMiddleware {
+	if path == "" {
+		path = "/metrics"
+	}
+	return &metrics{
+		BaseMiddleware: network.NewBaseMiddleware(),
+		path:           path,
+		token:          token,
+	}
+}
+
+func (m *metrics) Attach(engine *gin.Engine) {
+	if m.token != "" {
+		engine.GET(m.path, m.expose(promhttp.Handler()))
+	}
+	engine.Use(m.Handler)
+}
+
+func (m *metrics) Handler(ctx *gin.Context) {
+	start := time.Now()
+
+	ctx.Next()
+
+	route := ctx.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	code := string(network.GetResCode(ctx))
+	method := ctx.Request.Method
+	status := strconv.Itoa(ctx.Writer.Status())
+
+	httpRequests.WithLabelValues(method, route, status, code).Inc()
+	httpRequestDuration.WithLabelValues(method, route, code).Observe(time.Since(start).Seconds())
+}
+
+func (m *metrics) expose(handler http.Handler) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, ok := strings.CutPrefix(ctx.GetHeader(network.AuthorizationHeader), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(m.token)) != 1 {
+			m.Send(ctx).UnauthorizedError("permission denied: invalid metrics token", nil)
+			return
+		}
+		handler.ServeHTTP(ctx.Writer, ctx.Request)
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMetricsMiddleware_RecordsRoute(t *testing.T) {
+	counter := httpRequests.WithLabelValues("GET", "/blog/id/:id", "200", "10000")
+	before := testutil.ToFloat64(counter)
+
+	rr := network.MockTestRootMiddlewareWithUrl(t, "/blog/id/:id", "/blog/id/123",
+		NewMetrics("", "secret"),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+}
+
+func TestMetricsMiddleware_Endpoint(t *testing.T) {
+	rr := network.MockTestRootMiddlewareWithUrl(t, "/blog/id/:id", "/metrics",
+		NewMetrics("/metrics", "secret"),
+		network.MockSuccessMsgHandler("success"),
+		primitive.E{Key: network.AuthorizationHeader, Value: "Bearer secret"},
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "http_requests_total")
+}
+
+func TestMetricsMiddleware_Endpoint_InvalidToken(t *testing.T) {
+	rr := network.MockTestRootMiddlewareWithUrl(t, "/blog/id/:id", "/metrics",
+		NewMetrics("/metrics", "secret"),
+		network.MockSuccessMsgHandler("success"),
+		primitive.E{Key: network.AuthorizationHeader, Value: "Bearer wrong"},
+	)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestMetricsMiddleware_Endpoint_Disabled(t *testing.T) {
+	rr := network.MockTestRootMiddlewareWithUrl(t, "/blog/id/:id", "/metrics",
+		NewMetrics("/metrics", ""),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/ratelimit"
+)
+
+type rateLimit struct {
+	network.BaseMiddleware
+	limiter ratelimit.Limiter
+	rule    network.RateLimit
+}
+
+// applies the rule to all the routes mounted after it, a rule without requests disables it
+func NewRateLimit(limiter ratelimit.Limiter, rule network.RateLimit) network.RootMiddleware {
+	return &rateLimit{
+		BaseMiddleware: network.NewBaseMiddleware(),
+		limiter:        limiter,
+		rule:           rule,
+	}
+}
+
+func (m *rateLimit) Attach(engine *gin.Engine) {
+	if m.rule.Requests > 0 {
+		engine.Use(m.Handler)
+	}
+}
+
+func (m *rateLimit) Handler(ctx *gin.Context) {
+	limit(ctx, m, m.limiter, m.rule)
+}
+
+type rateLimitProvider struct {
+	network.BaseMiddleware
+	limiter ratelimit.Limiter
+}
+
+func NewRateLimitProvider(limiter ratelimit.Limiter) network.RateLimitProvider {
+	return &rateLimitProvider{
+		BaseMiddleware: network.NewBaseMiddleware(),
+		limiter:        limiter,
+	}
+}
+
+// a rule without requests disables the limit of the route
+func (p *rateLimitProvider) Middleware(rule network.RateLimit) gin.HandlerFunc {
+	if rule.Requests <= 0 {
+		return func(ctx *gin.Context) {
+			ctx.Next()
+		}
+	}
+	return func(ctx *gin.Context) {
+		limit(ctx, p, p.limiter, rule)
+	}
+}
+
+// the request is let through when the limiter fails, the limits should not take the api down
+func limit(ctx *gin.Context, sender network.ResponseSender, limiter ratelimit.Limiter, rule network.RateLimit) {
+	key := rule.Key(ctx)
+	if key == "" {
+		ctx.Next()
+		return
+	}
+
+	result, err := limiter.Allow(ctx.Request.Context(), rule.Name+":"+key, rule.Requests, rule.Window)
+	if err != nil {
+		ctx.Error(err)
+		ctx.Next()
+		return
+	}
+
+	reset := seconds(result.Reset)
+	ctx.Header(network.RateLimitLimitHeader, strconv.Itoa(result.Limit))
+	ctx.Header(network.RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+	ctx.Header(network.RateLimitResetHeader, reset)
+
+	if !result.Allowed {
+		ctx.Header(network.RetryAfterHeader, reset)
+		sender.Send(ctx).TooManyRequestsError("too many requests, retry after "+reset+" seconds", nil)
+		return
+	}
+
+	ctx.Next()
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/ratelimit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limiter := ratelimit.NewMemoryLimiter()
+	rule := network.RateLimit{
+		Name:     "api",
+		Requests: 2,
+		Window:   time.Minute,
+		Key:      network.RateLimitByIP,
+	}
+
+	for i := 0; i < 2; i++ {
+		rr := network.MockTestRootMiddleware(t, NewRateLimit(limiter, rule), network.MockSuccessMsgHandler("success"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "2", rr.Header().Get(network.RateLimitLimitHeader))
+	}
+
+	rr := network.MockTestRootMiddleware(t, NewRateLimit(limiter, rule), network.MockSuccessMsgHandler("success"))
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get(network.RateLimitRemainingHeader))
+	assert.Equal(t, "60", rr.Header().Get(network.RetryAfterHeader))
+	assert.Contains(t, rr.Body.String(), `"message":"too many requests, retry after 60 seconds"`)
+}
+
+func TestRateLimitMiddleware_Disabled(t *testing.T) {
+	rule := network.RateLimit{Name: "api", Key: network.RateLimitByIP}
+
+	rr := network.MockTestRootMiddleware(t, NewRateLimit(ratelimit.NewMemoryLimiter(), rule), network.MockSuccessMsgHandler("success"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get(network.RateLimitLimitHeader))
+}
+
+func TestRateLimitProvider_KeyedByHeader(t *testing.T) {
+	limiter := ratelimit.NewMemoryLimiter()
+	rule := network.RateLimit{
+		Name:     "signin",
+		Requests: 1,
+		Window:   time.Minute,
+		Key:      func(ctx *gin.Context) string { return ctx.GetHeader(network.ApiKeyHeader) },
+	}
+	handler := NewRateLimitProvider(limiter).Middleware(rule)
+
+	send := func(key string) int {
+		rr := network.MockTestHandler(t, "GET", "/", "/", "", handler, primitive.E{Key: network.ApiKeyHeader, Value: key})
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("key-1"))
+	assert.Equal(t, http.StatusTooManyRequests, send("key-1"))
+	assert.Equal(t, http.StatusOK, send("key-2"))
+	// requests without a key are not limited
+	assert.Equal(t, http.StatusOK, send(""))
+	assert.Equal(t, http.StatusOK, send(""))
+}
+
+func TestRateLimitProvider_Disabled(t *testing.T) {
+	rule := network.RateLimit{Name: "signin", Key: network.RateLimitByIP}
+	handler := NewRateLimitProvider(ratelimit.NewMemoryLimiter()).Middleware(rule)
+
+	rr := network.MockTestHandler(t, "GET", "/", "/", "", handler)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get(network.RateLimitLimitHeader))
+}
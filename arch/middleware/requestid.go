@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/arch/network"
+)
+
+type requestId struct {
+	network.BaseMiddleware
+}
+
+func NewRequestId() network.RootMiddleware {
+	return &requestId{
+		BaseMiddleware: network.NewBaseMiddleware(),
+	}
+}
+
+func (m *requestId) Attach(engine *gin.Engine) {
+	engine.Use(m.Handler)
+}
+
+func (m *requestId) Handler(ctx *gin.Context) {
+	id := ctx.GetHeader(network.RequestIdHeader)
+	if !network.IsValidRequestId(id) {
+		id = network.NewRequestId()
+	}
+
+	network.SetRequestId(ctx, id)
+	ctx.Header(network.RequestIdHeader, id)
+
+	ctx.Next()
+}
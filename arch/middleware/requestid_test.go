@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRequestIdMiddleware_Generate(t *testing.T) {
+	var id string
+	mockHandler := func(ctx *gin.Context) {
+		id = network.GetRequestId(ctx)
+		assert.Equal(t, id, network.RequestIdFromContext(ctx.Request.Context()))
+		network.NewResponseSender().Send(ctx).SuccessMsgResponse("success")
+	}
+
+	rr := network.MockTestRootMiddleware(t, NewRequestId(), mockHandler)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, rr.Header().Get(network.RequestIdHeader))
+}
+
+func TestRequestIdMiddleware_Accept(t *testing.T) {
+	rr := network.MockTestRootMiddleware(t, NewRequestId(),
+		network.MockSuccessMsgHandler("success"),
+		primitive.E{Key: network.RequestIdHeader, Value: "abc-123"},
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "abc-123", rr.Header().Get(network.RequestIdHeader))
+}
+
+func TestRequestIdMiddleware_RejectInvalid(t *testing.T) {
+	rr := network.MockTestRootMiddleware(t, NewRequestId(),
+		network.MockSuccessMsgHandler("success"),
+		primitive.E{Key: network.RequestIdHeader, Value: "abc 123\n"},
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotEqual(t, "abc 123\n", rr.Header().Get(network.RequestIdHeader))
+	assert.Len(t, rr.Header().Get(network.RequestIdHeader), 32)
+}
+
+func TestRequestIdMiddleware_ErrorBody(t *testing.T) {
+	mockHandler := func(ctx *gin.Context) {
+		network.NewResponseSender().Send(ctx).BadRequestError("bad request", nil)
+	}
+
+	rr := network.MockTestRootMiddleware(t, NewRequestId(), mockHandler,
+		primitive.E{Key: network.RequestIdHeader, Value: "abc-123"},
+	)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"requestId":"abc-123"`)
+}
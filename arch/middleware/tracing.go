@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/unusualcodeorg/goserve/arch/middleware"
+
+type tracing struct {
+	network.BaseMiddleware
+}
+
+func NewTracing() network.RootMiddleware {
+	return &tracing{
+		BaseMiddleware: network.NewBaseMiddleware(),
+	}
+}
+
+func (m *tracing) Attach(engine *gin.Engine) {
+	engine.Use(m.Handler)
+}
+
+func (m *tracing) Handler(ctx *gin.Context) {
+	parent := otel.GetTextMapPropagator().Extract(
+		ctx.Request.Context(),
+		propagation.HeaderCarrier(ctx.Request.Header),
+	)
+
+	route := ctx.FullPath()
+	spanName := fmt.Sprintf("%s %s", ctx.Request.Method, route)
+	if route == "" {
+		spanName = ctx.Request.Method
+	}
+
+	spanCtx, span := otel.Tracer(tracerName).Start(parent, spanName,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(ctx.Request.Method),
+			semconv.HTTPRoute(route),
+			semconv.URLPath(ctx.Request.URL.Path),
+		),
+	)
+	defer span.End()
+
+	if id := network.GetRequestId(ctx); id != "" {
+		span.SetAttributes(attribute.String("request.id", id))
+	}
+
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
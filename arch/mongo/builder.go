@@ -2,6 +2,7 @@ package mongo
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -10,12 +11,14 @@ import (
 type QueryBuilder[T any] interface {
 	GetCollection() *mongo.Collection
 	SingleQuery() Query[T]
+	SingleQueryContext(ctx context.Context) Query[T]
 	Query(context context.Context) Query[T]
 }
 
 type queryBuilder[T any] struct {
 	collection *mongo.Collection
 	timeout    time.Duration
+	logger     *slog.Logger
 }
 
 func (c *queryBuilder[T]) GetCollection() *mongo.Collection {
@@ -23,16 +26,22 @@ func (c *queryBuilder[T]) GetCollection() *mongo.Collection {
 }
 
 func (c *queryBuilder[T]) SingleQuery() Query[T] {
-	return newSingleQuery[T](c.collection, c.timeout)
+	return newSingleQuery[T](context.Background(), c.collection, c.timeout, c.logger)
+}
+
+// same as SingleQuery but the query becomes part of the request trace and cancellation
+func (c *queryBuilder[T]) SingleQueryContext(ctx context.Context) Query[T] {
+	return newSingleQuery[T](ctx, c.collection, c.timeout, c.logger)
 }
 
 func (c *queryBuilder[T]) Query(context context.Context) Query[T] {
-	return newQuery[T](context, c.collection)
+	return newQuery[T](context, c.collection, c.logger)
 }
 
 func NewQueryBuilder[T any](db Database, collectionName string) QueryBuilder[T] {
 	return &queryBuilder[T]{
 		collection: db.GetInstance().Collection(collectionName),
 		timeout:    db.GetInstance().config.Timeout,
+		logger:     db.GetInstance().logger,
 	}
 }
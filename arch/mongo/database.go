@@ -4,9 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
+	"github.com/unusualcodeorg/goserve/arch/health"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -33,18 +34,21 @@ type Database interface {
 	GetInstance() *database
 	Connect()
 	Disconnect()
+	RegisterHealthCheck(registry health.Registry)
 }
 
 type database struct {
 	*mongo.Database
 	context context.Context
 	config  DbConfig
+	logger  *slog.Logger
 }
 
-func NewDatabase(ctx context.Context, config DbConfig) Database {
+func NewDatabase(ctx context.Context, config DbConfig, logger *slog.Logger) Database {
 	db := database{
 		context: ctx,
 		config:  config,
+		logger:  logger.With("component", "mongo"),
 	}
 	return &db
 }
@@ -63,28 +67,38 @@ func (db *database) Connect() {
 	clientOptions.SetMaxPoolSize(uint64(db.config.MaxPoolSize))
 	clientOptions.SetMaxPoolSize(uint64(db.config.MinPoolSize))
 
-	fmt.Println("connecting mongo...")
+	db.logger.Info("connecting mongo")
 	client, err := mongo.Connect(db.context, clientOptions)
 	if err != nil {
-		log.Fatal("connection to mongo failed!: ", err)
+		db.logger.Error("connection to mongo failed", "error", err)
+		panic(err)
 	}
 
 	err = client.Ping(db.context, nil)
 	if err != nil {
-		log.Panic("pinging to mongo failed!: ", err)
+		db.logger.Error("pinging to mongo failed", "error", err)
+		panic(err)
 	}
-	fmt.Println("connected to mongo!")
+	db.logger.Info("connected to mongo")
 
 	db.Database = client.Database(db.config.Name)
 }
 
+// runs as a shutdown hook, a failure is only logged so that the other hooks still run
 func (db *database) Disconnect() {
-	fmt.Println("disconnecting mongo...")
+	db.logger.Info("disconnecting mongo")
 	err := db.Client().Disconnect(db.context)
 	if err != nil {
-		log.Panic(err)
+		db.logger.Error("disconnecting mongo failed", "error", err)
+		return
 	}
-	fmt.Println("disconnected mongo")
+	db.logger.Info("disconnected mongo")
+}
+
+func (db *database) RegisterHealthCheck(registry health.Registry) {
+	registry.Register("mongo", func(ctx context.Context) error {
+		return db.Client().Ping(ctx, nil)
+	})
 }
 
 func NewObjectID(id string) (primitive.ObjectID, error) {
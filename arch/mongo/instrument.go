@@ -0,0 +1,131 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/unusualcodeorg/goserve/arch/mongo"
+
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "mongo_query_duration_seconds",
+	Help:    "Duration of the mongo queries.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"collection", "operation", "status"})
+
+// records the span and the duration metric of a query
+type operation struct {
+	span       trace.Span
+	collection string
+	name       string
+	start      time.Time
+}
+
+func startOperation(ctx context.Context, collection string, name string, filter bson.M) (context.Context, *operation) {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemMongoDB,
+		semconv.DBCollectionName(collection),
+		semconv.DBOperationName(name),
+	}
+	if filter != nil {
+		attrs = append(attrs, attribute.String("db.mongodb.filter_shape", FilterShape(filter)))
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, collection+"."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	return ctx, &operation{
+		span:       span,
+		collection: collection,
+		name:       name,
+		start:      time.Now(),
+	}
+}
+
+// a miss is a normal result of the lookups, so it does not fail the span
+func (o *operation) end(err error) {
+	status := operationStatus(err)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		o.span.SetAttributes(attribute.Bool("db.mongodb.found", false))
+	} else if err != nil {
+		o.span.RecordError(err)
+		o.span.SetStatus(codes.Error, err.Error())
+	}
+	o.span.End()
+	queryDuration.WithLabelValues(o.collection, o.name, status).Observe(time.Since(o.start).Seconds())
+}
+
+// the misses are counted apart, so that they do not raise the error rate
+func operationStatus(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "not_found"
+	}
+	return "error"
+}
+
+// FilterShape replaces the values with ? so that the span does not carry the user data
+// Example -> {"email": "a@b.com", "status": true} gives {email:?,status:?}
+func FilterShape(filter bson.M) string {
+	var b strings.Builder
+	writeShape(&b, filter)
+	return b.String()
+}
+
+func writeShape(b *strings.Builder, value any) {
+	switch v := value.(type) {
+	case bson.M:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString("{")
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(k + ":")
+			writeShape(b, v[k])
+		}
+		b.WriteString("}")
+	case bson.D:
+		b.WriteString("{")
+		for i, e := range v {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(e.Key + ":")
+			writeShape(b, e.Value)
+		}
+		b.WriteString("}")
+	case bson.A:
+		b.WriteString("[")
+		for i, e := range v {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			writeShape(b, e)
+		}
+		b.WriteString("]")
+	default:
+		b.WriteString("?")
+	}
+}
@@ -0,0 +1,36 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestFilterShape(t *testing.T) {
+	tests := []struct {
+		filter   bson.M
+		expected string
+	}{
+		{bson.M{}, "{}"},
+		{bson.M{"status": true, "email": "a@b.com"}, "{email:?,status:?}"},
+		{bson.M{"_id": bson.M{"$in": []primitive.ObjectID{primitive.NewObjectID()}}}, "{_id:{$in:?}}"},
+		{bson.M{"$or": bson.A{bson.M{"a": 1}, bson.M{"b": 2}}}, "{$or:[{a:?},{b:?}]}"},
+		{bson.M{"sort": bson.D{{Key: "z", Value: 1}, {Key: "a", Value: -1}}}, "{sort:{z:?,a:?}}"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, FilterShape(tt.filter))
+	}
+}
+
+func TestOperationStatus(t *testing.T) {
+	assert.Equal(t, "ok", operationStatus(nil))
+	assert.Equal(t, "not_found", operationStatus(mongo.ErrNoDocuments))
+	assert.Equal(t, "not_found", operationStatus(fmt.Errorf("find user: %w", mongo.ErrNoDocuments)))
+	assert.Equal(t, "error", operationStatus(errors.New("connection refused")))
+}
@@ -0,0 +1,136 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MockQueryBuilder[T any] struct {
+	mock.Mock
+}
+
+func (m *MockQueryBuilder[T]) GetCollection() *mongo.Collection {
+	args := m.Called()
+	return args.Get(0).(*mongo.Collection)
+}
+
+func (m *MockQueryBuilder[T]) SingleQuery() Query[T] {
+	args := m.Called()
+	return args.Get(0).(Query[T])
+}
+
+func (m *MockQueryBuilder[T]) SingleQueryContext(ctx context.Context) Query[T] {
+	args := m.Called(ctx)
+	return args.Get(0).(Query[T])
+}
+
+func (m *MockQueryBuilder[T]) Query(ctx context.Context) Query[T] {
+	args := m.Called(ctx)
+	return args.Get(0).(Query[T])
+}
+
+type MockQuery[T any] struct {
+	mock.Mock
+}
+
+func (m *MockQuery[T]) Close() {
+	m.Called()
+}
+
+func (m *MockQuery[T]) CreateIndexes(indexes []mongo.IndexModel) error {
+	args := m.Called(indexes)
+	return args.Error(0)
+}
+
+func (m *MockQuery[T]) FindOne(filter bson.M, opts *options.FindOneOptions) (*T, error) {
+	args := m.Called(filter, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*T), args.Error(1)
+}
+
+func (m *MockQuery[T]) FindAll(filter bson.M, opts *options.FindOptions) ([]*T, error) {
+	args := m.Called(filter, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*T), args.Error(1)
+}
+
+func (m *MockQuery[T]) FindPaginated(filter bson.M, page int64, limit int64, opts *options.FindOptions) ([]*T, error) {
+	args := m.Called(filter, page, limit, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*T), args.Error(1)
+}
+
+func (m *MockQuery[T]) InsertOne(doc *T) (*primitive.ObjectID, error) {
+	args := m.Called(doc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*primitive.ObjectID), args.Error(1)
+}
+
+func (m *MockQuery[T]) InsertAndRetrieveOne(doc *T) (*T, error) {
+	args := m.Called(doc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*T), args.Error(1)
+}
+
+func (m *MockQuery[T]) InsertMany(docs []*T) ([]primitive.ObjectID, error) {
+	args := m.Called(docs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]primitive.ObjectID), args.Error(1)
+}
+
+func (m *MockQuery[T]) InsertAndRetrieveMany(docs []*T) ([]*T, error) {
+	args := m.Called(docs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*T), args.Error(1)
+}
+
+func (m *MockQuery[T]) UpdateOne(filter bson.M, update bson.M) (*mongo.UpdateResult, error) {
+	args := m.Called(filter, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mongo.UpdateResult), args.Error(1)
+}
+
+func (m *MockQuery[T]) UpdateMany(filter bson.M, update bson.M) (*mongo.UpdateResult, error) {
+	args := m.Called(filter, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mongo.UpdateResult), args.Error(1)
+}
+
+func (m *MockQuery[T]) DeleteOne(filter bson.M) (*mongo.DeleteResult, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mongo.DeleteResult), args.Error(1)
+}
+
+func (m *MockQuery[T]) DeleteMany(filter bson.M) (*mongo.DeleteResult, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mongo.DeleteResult), args.Error(1)
+}
@@ -3,6 +3,7 @@ package mongo
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -24,27 +25,31 @@ type Query[T any] interface {
 	UpdateOne(filter bson.M, update bson.M) (*mongo.UpdateResult, error)
 	UpdateMany(filter bson.M, update bson.M) (*mongo.UpdateResult, error)
 	DeleteOne(filter bson.M) (*mongo.DeleteResult, error)
+	DeleteMany(filter bson.M) (*mongo.DeleteResult, error)
 }
 
 type query[T any] struct {
 	collection *mongo.Collection
 	context    context.Context
 	cancel     context.CancelFunc
+	logger     *slog.Logger
 }
 
-func newSingleQuery[T any](collection *mongo.Collection, timeout time.Duration) Query[T] {
-	context, cancel := context.WithTimeout(context.Background(), timeout)
+func newSingleQuery[T any](parent context.Context, collection *mongo.Collection, timeout time.Duration, logger *slog.Logger) Query[T] {
+	context, cancel := context.WithTimeout(parent, timeout)
 	return &query[T]{
 		context:    context,
 		cancel:     cancel,
 		collection: collection,
+		logger:     logger,
 	}
 }
 
-func newQuery[T any](context context.Context, collection *mongo.Collection) Query[T] {
+func newQuery[T any](context context.Context, collection *mongo.Collection, logger *slog.Logger) Query[T] {
 	return &query[T]{
 		context:    context,
 		collection: collection,
+		logger:     logger,
 	}
 }
 
@@ -56,16 +61,22 @@ func (q *query[T]) Close() {
 
 func (q *query[T]) CreateIndexes(indexes []mongo.IndexModel) error {
 	defer q.Close()
-	fmt.Println("database indexing for: " + q.collection.Name())
+	q.logger.Info("database indexing", "collection", q.collection.Name())
 	result, err := q.collection.Indexes().CreateMany(q.context, indexes)
-	fmt.Println(q.collection.Name(), result)
-	return err
+	if err != nil {
+		q.logger.Error("database indexing failed", "collection", q.collection.Name(), "error", err)
+		return err
+	}
+	q.logger.Info("database indexed", "collection", q.collection.Name(), "indexes", result)
+	return nil
 }
 
 func (q *query[T]) FindOne(filter bson.M, opts *options.FindOneOptions) (*T, error) {
 	defer q.Close()
+	ctx, op := startOperation(q.context, q.collection.Name(), "FindOne", filter)
 	var doc T
-	err := q.collection.FindOne(q.context, filter, opts).Decode(&doc)
+	err := q.collection.FindOne(ctx, filter, opts).Decode(&doc)
+	op.end(err)
 	if err != nil {
 		return nil, err
 	}
@@ -73,17 +84,18 @@ func (q *query[T]) FindOne(filter bson.M, opts *options.FindOneOptions) (*T, err
 	return &doc, nil
 }
 
-func (q *query[T]) FindAll(filter bson.M, opts *options.FindOptions) ([]*T, error) {
+func (q *query[T]) FindAll(filter bson.M, opts *options.FindOptions) (docs []*T, err error) {
 	defer q.Close()
-	cursor, err := q.collection.Find(q.context, filter, opts)
+	ctx, op := startOperation(q.context, q.collection.Name(), "FindAll", filter)
+	defer func() { op.end(err) }()
+
+	cursor, err := q.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, fmt.Errorf("error executing query: %w", err)
 	}
-	defer cursor.Close(q.context)
-
-	var docs []*T
+	defer cursor.Close(ctx)
 
-	for cursor.Next(q.context) {
+	for cursor.Next(ctx) {
 		var result T
 		err := cursor.Decode(&result)
 		if err != nil {
@@ -99,8 +111,11 @@ func (q *query[T]) FindAll(filter bson.M, opts *options.FindOptions) ([]*T, erro
 	return docs, nil
 }
 
-func (q *query[T]) FindPaginated(filter bson.M, page int64, limit int64, opts *options.FindOptions) ([]*T, error) {
+func (q *query[T]) FindPaginated(filter bson.M, page int64, limit int64, opts *options.FindOptions) (docs []*T, err error) {
 	defer q.Close()
+	ctx, op := startOperation(q.context, q.collection.Name(), "FindPaginated", filter)
+	defer func() { op.end(err) }()
+
 	skip := (page - 1) * limit
 
 	if opts == nil {
@@ -109,15 +124,13 @@ func (q *query[T]) FindPaginated(filter bson.M, page int64, limit int64, opts *o
 	opts.SetSkip(skip)
 	opts.SetLimit(int64(limit))
 
-	cursor, err := q.collection.Find(q.context, filter, opts)
+	cursor, err := q.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, fmt.Errorf("error executing query: %w", err)
 	}
-	defer cursor.Close(q.context)
-
-	var docs []*T
+	defer cursor.Close(ctx)
 
-	for cursor.Next(q.context) {
+	for cursor.Next(ctx) {
 		var result T
 		err := cursor.Decode(&result)
 		if err != nil {
@@ -135,7 +148,9 @@ func (q *query[T]) FindPaginated(filter bson.M, page int64, limit int64, opts *o
 
 func (q *query[T]) InsertOne(doc *T) (*primitive.ObjectID, error) {
 	defer q.Close()
-	result, err := q.collection.InsertOne(q.context, doc)
+	ctx, op := startOperation(q.context, q.collection.Name(), "InsertOne", nil)
+	result, err := q.collection.InsertOne(ctx, doc)
+	op.end(err)
 	if err != nil {
 		return nil, err
 	}
@@ -150,7 +165,9 @@ func (q *query[T]) InsertOne(doc *T) (*primitive.ObjectID, error) {
 
 func (q *query[T]) InsertAndRetrieveOne(doc *T) (*T, error) {
 	defer q.Close()
-	result, err := q.collection.InsertOne(q.context, doc)
+	ctx, op := startOperation(q.context, q.collection.Name(), "InsertOne", nil)
+	result, err := q.collection.InsertOne(ctx, doc)
+	op.end(err)
 	if err != nil {
 		return nil, err
 	}
@@ -171,7 +188,9 @@ func (q *query[T]) InsertMany(docs []*T) ([]primitive.ObjectID, error) {
 		iDocs = append(iDocs, doc)
 	}
 
-	result, err := q.collection.InsertMany(q.context, iDocs)
+	ctx, op := startOperation(q.context, q.collection.Name(), "InsertMany", nil)
+	result, err := q.collection.InsertMany(ctx, iDocs)
+	op.end(err)
 	if err != nil {
 		return nil, err
 	}
@@ -196,7 +215,9 @@ func (q *query[T]) InsertAndRetrieveMany(docs []*T) ([]*T, error) {
 		iDocs = append(iDocs, doc)
 	}
 
-	result, err := q.collection.InsertMany(q.context, iDocs)
+	ctx, op := startOperation(q.context, q.collection.Name(), "InsertMany", nil)
+	result, err := q.collection.InsertMany(ctx, iDocs)
+	op.end(err)
 	if err != nil {
 		return nil, err
 	}
@@ -216,7 +237,9 @@ func (q *query[T]) InsertAndRetrieveMany(docs []*T) ([]*T, error) {
  */
 func (q *query[T]) UpdateOne(filter bson.M, update bson.M) (*mongo.UpdateResult, error) {
 	defer q.Close()
-	result, err := q.collection.UpdateOne(q.context, filter, update)
+	ctx, op := startOperation(q.context, q.collection.Name(), "UpdateOne", filter)
+	result, err := q.collection.UpdateOne(ctx, filter, update)
+	op.end(err)
 	if err != nil {
 		return nil, err
 	}
@@ -228,7 +251,9 @@ func (q *query[T]) UpdateOne(filter bson.M, update bson.M) (*mongo.UpdateResult,
  */
 func (q *query[T]) UpdateMany(filter bson.M, update bson.M) (*mongo.UpdateResult, error) {
 	defer q.Close()
-	result, err := q.collection.UpdateMany(q.context, filter, update)
+	ctx, op := startOperation(q.context, q.collection.Name(), "UpdateMany", filter)
+	result, err := q.collection.UpdateMany(ctx, filter, update)
+	op.end(err)
 	if err != nil {
 		return nil, err
 	}
@@ -237,7 +262,21 @@ func (q *query[T]) UpdateMany(filter bson.M, update bson.M) (*mongo.UpdateResult
 
 func (q *query[T]) DeleteOne(filter bson.M) (*mongo.DeleteResult, error) {
 	defer q.Close()
-	result, err := q.collection.DeleteOne(q.context, filter)
+	ctx, op := startOperation(q.context, q.collection.Name(), "DeleteOne", filter)
+	result, err := q.collection.DeleteOne(ctx, filter)
+	op.end(err)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (q *query[T]) DeleteMany(filter bson.M) (*mongo.DeleteResult, error) {
+	defer q.Close()
+	ctx, op := startOperation(q.context, q.collection.Name(), "DeleteMany", filter)
+	result, err := q.collection.DeleteMany(ctx, filter)
+	op.end(err)
 	if err != nil {
 		return nil, err
 	}
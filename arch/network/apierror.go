@@ -4,12 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type apiError struct {
-	Code    int
-	Message string
-	Err     error
+	Code       int
+	Message    string
+	Err        error
+	RetryAfter time.Duration
 }
 
 func (e *apiError) GetCode() int {
@@ -20,6 +22,11 @@ func (e *apiError) GetMessage() string {
 	return e.Message
 }
 
+// the wait sent in the Retry-After header, zero if it has none
+func (e *apiError) GetRetryAfter() time.Duration {
+	return e.RetryAfter
+}
+
 func (e *apiError) Error() string {
 	if e.Err != nil {
 		return fmt.Sprintf("%d - %s: %v", e.Code, e.Message, e.Err)
@@ -59,6 +66,17 @@ func NewNotFoundError(message string, err error) ApiError {
 	return newApiError(http.StatusNotFound, message, err)
 }
 
+func NewTooManyRequestsError(message string, err error) ApiError {
+	return newApiError(http.StatusTooManyRequests, message, err)
+}
+
+// the sender sets the Retry-After header from the wait
+func NewTooManyRequestsRetryError(message string, retryAfter time.Duration, err error) ApiError {
+	apiError := newApiError(http.StatusTooManyRequests, message, err).(*apiError)
+	apiError.RetryAfter = retryAfter
+	return apiError
+}
+
 func NewInternalServerError(message string, err error) ApiError {
 	return newApiError(http.StatusInternalServerError, message, err)
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -62,4 +63,21 @@ func TestNewInternalServerError(t *testing.T) {
 	assert.Equal(t, message, apiErr.GetMessage())
 	assert.EqualError(t, apiErr, fmt.Sprintf("%d - %s: %v", http.StatusInternalServerError, message, err))
 	assert.ErrorIs(t, apiErr, err)
-}
\ No newline at end of file
+}
+func TestNewTooManyRequestsError(t *testing.T) {
+	message := "Too many requests"
+	apiErr := NewTooManyRequestsError(message, nil)
+
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.GetCode())
+	assert.Equal(t, message, apiErr.GetMessage())
+	assert.EqualError(t, apiErr, fmt.Sprintf("%d - %s: %v", http.StatusTooManyRequests, message, message))
+}
+
+func TestNewTooManyRequestsRetryError(t *testing.T) {
+	message := "Too many requests"
+	apiErr := NewTooManyRequestsRetryError(message, 90*time.Second, nil)
+
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.GetCode())
+	assert.Equal(t, message, apiErr.GetMessage())
+	assert.Equal(t, 90*time.Second, apiErr.(*apiError).GetRetryAfter())
+}
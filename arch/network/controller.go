@@ -28,6 +28,7 @@ func (c *baseController) Authentication() gin.HandlerFunc {
 	return c.authProvider.Middleware()
 }
 
-func (c *baseController) Authorization(role string) gin.HandlerFunc {
-	return c.authorizeProvider.Middleware(role)
+// the options like the verified email can be passed along with the roles
+func (c *baseController) Authorization(roles ...string) gin.HandlerFunc {
+	return c.authorizeProvider.Middleware(roles...)
 }
@@ -3,4 +3,10 @@ package network
 const (
 	ApiKeyHeader        = "x-api-key"
 	AuthorizationHeader = "Authorization"
+	RequestIdHeader     = "X-Request-ID"
+	RetryAfterHeader    = "Retry-After"
+	// ref: https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
 )
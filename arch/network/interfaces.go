@@ -2,6 +2,7 @@ package network
 
 import (
 	"context"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -19,6 +20,7 @@ type Response interface {
 	GetStatus() int
 	GetMessage() string
 	GetData() any
+	GetRequestId() string
 }
 
 type SendResponse interface {
@@ -28,6 +30,7 @@ type SendResponse interface {
 	ForbiddenError(message string, err error)
 	UnauthorizedError(message string, err error)
 	NotFoundError(message string, err error)
+	TooManyRequestsError(message string, err error)
 	InternalServerError(message string, err error)
 	MixedError(err error)
 }
@@ -41,7 +44,7 @@ type BaseController interface {
 	ResponseSender
 	Path() string
 	Authentication() gin.HandlerFunc
-	Authorization(role string) gin.HandlerFunc
+	Authorization(roles ...string) gin.HandlerFunc
 }
 
 type Controller interface {
@@ -100,12 +103,20 @@ type ParamNMiddlewareProvider[T any] interface {
 
 type AuthenticationProvider Param0MiddlewareProvider
 type AuthorizationProvider ParamNMiddlewareProvider[string]
+type RateLimitProvider Param1MiddlewareProvider[RateLimit]
+type PermissionProvider Param1MiddlewareProvider[ApiPermission]
+
+type ShutdownHook = func()
 
 type BaseRouter interface {
 	GetEngine() *gin.Engine
 	RegisterValidationParsers(tagNameFunc validator.TagNameFunc)
 	LoadRootMiddlewares(middlewares []RootMiddleware)
-	Start(ip string, port uint16)
+	SetShutdownTimeout(timeout time.Duration)
+	RegisterShutdownHook(hook ShutdownHook)
+	RegisterDrainHook(hook ShutdownHook)
+	Shutdown()
+	Start(ip string, port uint16, options ...ServerOption)
 }
 
 type Router interface {
@@ -118,6 +129,8 @@ type BaseModule[T any] interface {
 	RootMiddlewares() []RootMiddleware
 	AuthenticationProvider() AuthenticationProvider
 	AuthorizationProvider() AuthorizationProvider
+	RateLimitProvider() RateLimitProvider
+	PermissionProvider() PermissionProvider
 }
 
 type Module[T any] interface {
@@ -222,6 +222,39 @@ func MockTestAuthorizationProvider(
 	return rr
 }
 
+func MockTestPermissionProvider(
+	t *testing.T,
+	permission ApiPermission,
+	key RootMiddleware,
+	provider PermissionProvider,
+	handler gin.HandlerFunc,
+	headers ...primitive.E,
+) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+
+	rr := httptest.NewRecorder()
+	ctx, r := gin.CreateTestContext(rr)
+	key.Attach(r)
+	r.Use(provider.Middleware(permission))
+	r.GET("/", handler)
+
+	req, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatalf("could not create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	for _, h := range headers {
+		req.Header.Set(h.Key, h.Value.(string))
+	}
+
+	ctx.Request = req
+
+	r.ServeHTTP(rr, req)
+
+	return rr
+}
+
 func MockTestController(
 	t *testing.T, httpMethod, url, body string,
 	controller Controller,
@@ -285,3 +318,39 @@ func (m *MockAuthorizationProvider) Send(ctx *gin.Context) SendResponse {
 	args := m.Called(ctx)
 	return args.Get(0).(SendResponse)
 }
+
+type MockRateLimitProvider struct {
+	mock.Mock
+}
+
+func (m *MockRateLimitProvider) Debug() bool {
+	return true
+}
+
+func (m *MockRateLimitProvider) Middleware(rule RateLimit) gin.HandlerFunc {
+	args := m.Called(rule)
+	return args.Get(0).(gin.HandlerFunc)
+}
+
+func (m *MockRateLimitProvider) Send(ctx *gin.Context) SendResponse {
+	args := m.Called(ctx)
+	return args.Get(0).(SendResponse)
+}
+
+type MockPermissionProvider struct {
+	mock.Mock
+}
+
+func (m *MockPermissionProvider) Debug() bool {
+	return true
+}
+
+func (m *MockPermissionProvider) Middleware(permission ApiPermission) gin.HandlerFunc {
+	args := m.Called(permission)
+	return args.Get(0).(gin.HandlerFunc)
+}
+
+func (m *MockPermissionProvider) Send(ctx *gin.Context) SendResponse {
+	args := m.Called(ctx)
+	return args.Get(0).(SendResponse)
+}
@@ -0,0 +1,9 @@
+package network
+
+// access required from the api key to call the routes
+type ApiPermission struct {
+	// api version of the routes, it must be allowed to the api key. 0 accepts all the keys
+	Version int
+	// all of them must be granted to the api key
+	Permissions []string
+}
@@ -0,0 +1,22 @@
+package network
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// identifies the client the limit applies to, an empty key skips the limit
+type RateLimitKey = func(ctx *gin.Context) string
+
+type RateLimit struct {
+	// namespace of the counters, so the same client is counted separately for each rule
+	Name     string
+	Requests int
+	Window   time.Duration
+	Key      RateLimitKey
+}
+
+func RateLimitByIP(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
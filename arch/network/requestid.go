@@ -0,0 +1,63 @@
+package network
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const payloadRequestId = "requestId"
+
+type requestIdKey struct{}
+
+func NewRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// accepts the ids sent by the clients or proxies only if they are safe to log and echo
+func IsValidRequestId(id string) bool {
+	if len(id) == 0 || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		valid := (c >= 'a' && c <= 'z') ||
+			(c >= 'A' && c <= 'Z') ||
+			(c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.'
+		if !valid {
+			return false
+		}
+	}
+	return true
+}
+
+// stores the id on the gin context as well as on the request context for the downstream calls
+func SetRequestId(ctx *gin.Context, id string) {
+	ctx.Set(payloadRequestId, id)
+	ctx.Request = ctx.Request.WithContext(ContextWithRequestId(ctx.Request.Context(), id))
+}
+
+func GetRequestId(ctx *gin.Context) string {
+	return ctx.GetString(payloadRequestId)
+}
+
+func ContextWithRequestId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+func RequestIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if gctx, ok := ctx.(*gin.Context); ok {
+		return GetRequestId(gctx)
+	}
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
@@ -14,10 +14,11 @@ const (
 )
 
 type response struct {
-	ResCode ResCode `json:"code" binding:"required"`
-	Status  int     `json:"status" binding:"required"`
-	Message string  `json:"message" binding:"required"`
-	Data    any     `json:"data,omitempty" binding:"required,omitempty"`
+	ResCode   ResCode `json:"code" binding:"required"`
+	Status    int     `json:"status" binding:"required"`
+	Message   string  `json:"message" binding:"required"`
+	Data      any     `json:"data,omitempty" binding:"required,omitempty"`
+	RequestId string  `json:"requestId,omitempty"`
 }
 
 func (r *response) GetResCode() ResCode {
@@ -36,6 +37,10 @@ func (r *response) GetData() any {
 	return r.Data
 }
 
+func (r *response) GetRequestId() string {
+	return r.RequestId
+}
+
 func NewSuccessDataResponse(message string, data any) Response {
 	return &response{
 		ResCode: success_code,
@@ -85,6 +90,14 @@ func NewNotFoundResponse(message string) Response {
 	}
 }
 
+func NewTooManyRequestsResponse(message string) Response {
+	return &response{
+		ResCode: failue_code,
+		Status:  http.StatusTooManyRequests,
+		Message: message,
+	}
+}
+
 func NewInternalServerErrorResponse(message string) Response {
 	return &response{
 		ResCode: failue_code,
@@ -78,3 +78,13 @@ func TestNewInternalServerErrorResponse(t *testing.T) {
 	assert.Equal(t, 500, resp.GetStatus())
 	assert.Nil(t, resp.GetData())
 }
+
+func TestNewTooManyRequestsResponse(t *testing.T) {
+	message := "Too many requests"
+	resp := NewTooManyRequestsResponse(message)
+
+	assert.Equal(t, failue_code, resp.GetResCode())
+	assert.Equal(t, "Too many requests", resp.GetMessage())
+	assert.Equal(t, 429, resp.GetStatus())
+	assert.Nil(t, resp.GetData())
+}
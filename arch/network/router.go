@@ -1,22 +1,47 @@
 package network
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 )
 
+const defaultShutdownTimeout = 10 * time.Second
+
 type router struct {
-	engine *gin.Engine
+	engine          *gin.Engine
+	logger          *slog.Logger
+	shutdownTimeout time.Duration
+	shutdownHooks   []ShutdownHook
+	drainHooks      []ShutdownHook
+	shutdownOnce    sync.Once
 }
 
-func NewRouter(mode string) Router {
+// access logs are written by the middleware.NewAccessLog root middleware
+// the client ip is read from the forwarded headers only when the request comes from a trusted proxy,
+// nil trusts none of them so that the clients can not spoof their ip
+func NewRouter(mode string, trustedProxies []string, logger *slog.Logger) Router {
 	gin.SetMode(mode)
-	eng := gin.Default()
+	eng := gin.New()
+	if err := eng.SetTrustedProxies(trustedProxies); err != nil {
+		panic(err)
+	}
+	eng.Use(gin.Recovery())
 	r := router{
-		engine: eng,
+		engine:          eng,
+		logger:          logger.With("component", "server"),
+		shutdownTimeout: defaultShutdownTimeout,
 	}
 	return &r
 }
@@ -38,9 +63,87 @@ func (r *router) LoadControllers(controllers []Controller) {
 	}
 }
 
-func (r *router) Start(ip string, port uint16) {
+func (r *router) SetShutdownTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		r.shutdownTimeout = timeout
+	}
+}
+
+func (r *router) RegisterShutdownHook(hook ShutdownHook) {
+	r.shutdownHooks = append(r.shutdownHooks, hook)
+}
+
+// drain hooks run as soon as the shutdown starts, before the in-flight requests are drained
+func (r *router) RegisterDrainHook(hook ShutdownHook) {
+	r.drainHooks = append(r.drainHooks, hook)
+}
+
+// hooks run in the reverse order of registration, only once
+func (r *router) Shutdown() {
+	r.shutdownOnce.Do(func() {
+		for i := len(r.shutdownHooks) - 1; i >= 0; i-- {
+			r.shutdownHooks[i]()
+		}
+	})
+}
+
+func (r *router) Start(ip string, port uint16, options ...ServerOption) {
+	config := NewServerConfig(options...)
+	r.engine.UseH2C = config.H2C
+
 	address := fmt.Sprintf("%s:%d", ip, port)
-	r.engine.Run(address)
+	server, err := newHttpServer(address, r.engine.Handler(), config, r.logger)
+	if err != nil {
+		r.logger.Error("server failed", "error", err)
+		r.Shutdown()
+		return
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		r.logger.Info("server listening", "address", address, "tls", config.TLSEnabled())
+		var err error
+		if config.TLSEnabled() {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case sig := <-quit:
+		r.logger.Info("received signal", "signal", sig.String())
+	case err := <-serverErr:
+		r.logger.Error("server failed", "error", err)
+	}
+
+	r.drain(server)
+	r.Shutdown()
+}
+
+// stops accepting new connections and waits for the in-flight requests till the deadline
+func (r *router) drain(server *http.Server) {
+	for _, hook := range r.drainHooks {
+		hook()
+	}
+
+	r.logger.Info("shutting down server", "timeout", r.shutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), r.shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		r.logger.Warn("server forced to shutdown", "error", err)
+		server.Close()
+		return
+	}
+	r.logger.Info("server stopped")
 }
 
 func (r *router) RegisterValidationParsers(tagNameFunc validator.TagNameFunc) {
@@ -0,0 +1,101 @@
+package network
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter_ShutdownHooksReverseOrder(t *testing.T) {
+	r := NewRouter(gin.TestMode, nil, slog.Default())
+
+	var calls []string
+	r.RegisterShutdownHook(func() { calls = append(calls, "mongo") })
+	r.RegisterShutdownHook(func() { calls = append(calls, "redis") })
+	r.RegisterShutdownHook(func() { calls = append(calls, "nats") })
+
+	r.Shutdown()
+
+	assert.Equal(t, []string{"nats", "redis", "mongo"}, calls)
+}
+
+func TestRouter_ShutdownHooksRunOnce(t *testing.T) {
+	r := NewRouter(gin.TestMode, nil, slog.Default())
+
+	count := 0
+	r.RegisterShutdownHook(func() { count++ })
+
+	r.Shutdown()
+	r.Shutdown()
+
+	assert.Equal(t, 1, count)
+}
+
+func TestRouter_SetShutdownTimeout(t *testing.T) {
+	r := NewRouter(gin.TestMode, nil, slog.Default()).(*router)
+	assert.Equal(t, defaultShutdownTimeout, r.shutdownTimeout)
+
+	r.SetShutdownTimeout(0)
+	assert.Equal(t, defaultShutdownTimeout, r.shutdownTimeout)
+
+	r.SetShutdownTimeout(30 * time.Second)
+	assert.Equal(t, 30*time.Second, r.shutdownTimeout)
+}
+
+func TestRouter_DrainHooksRunBeforeShutdownHooks(t *testing.T) {
+	r := NewRouter(gin.TestMode, nil, slog.Default()).(*router)
+
+	var calls []string
+	r.RegisterShutdownHook(func() { calls = append(calls, "shutdown") })
+	r.RegisterDrainHook(func() { calls = append(calls, "drain") })
+
+	r.drain(&http.Server{})
+	r.Shutdown()
+
+	assert.Equal(t, []string{"drain", "shutdown"}, calls)
+}
+
+func TestRouter_RateLimitByIPIgnoresSpoofedHeader(t *testing.T) {
+	r := NewRouter(gin.TestMode, nil, slog.Default())
+	r.GetEngine().GET("/ip", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, RateLimitByIP(ctx))
+	})
+
+	for _, forwarded := range []string{"", "1.1.1.1", "2.2.2.2, 3.3.3.3"} {
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		if forwarded != "" {
+			req.Header.Set("X-Forwarded-For", forwarded)
+			req.Header.Set("X-Real-IP", forwarded)
+		}
+		rr := httptest.NewRecorder()
+		r.GetEngine().ServeHTTP(rr, req)
+		assert.Equal(t, "ip:10.0.0.1", rr.Body.String())
+	}
+}
+
+func TestRouter_RateLimitByIPTrustedProxy(t *testing.T) {
+	r := NewRouter(gin.TestMode, []string{"10.0.0.0/8"}, slog.Default())
+	r.GetEngine().GET("/ip", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, RateLimitByIP(ctx))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "1.1.1.1")
+	rr := httptest.NewRecorder()
+	r.GetEngine().ServeHTTP(rr, req)
+	assert.Equal(t, "ip:1.1.1.1", rr.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/ip", nil)
+	req.RemoteAddr = "192.168.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "1.1.1.1")
+	rr = httptest.NewRecorder()
+	r.GetEngine().ServeHTTP(rr, req)
+	assert.Equal(t, "ip:192.168.0.1", rr.Body.String())
+}
@@ -2,11 +2,16 @@ package network
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const payloadResCode = "resCode"
+
 type sender struct{}
 
 func NewResponseSender() ResponseSender {
@@ -53,6 +58,10 @@ func (s *send) NotFoundError(message string, err error) {
 	s.sendError(NewNotFoundError(message, err))
 }
 
+func (s *send) TooManyRequestsError(message string, err error) {
+	s.sendError(NewTooManyRequestsError(message, err))
+}
+
 func (s *send) InternalServerError(message string, err error) {
 	s.sendError(NewInternalServerError(message, err))
 }
@@ -73,6 +82,7 @@ func (s *send) MixedError(err error) {
 }
 
 func (s *send) sendResponse(response Response) {
+	s.context.Set(payloadResCode, response.GetResCode())
 	s.context.JSON(int(response.GetStatus()), response)
 	// this is needed since gin calls ctx.Next() inside the resposne handeling
 	// ref: https://github.com/gin-gonic/gin/issues/2221
@@ -91,6 +101,11 @@ func (s *send) sendError(err ApiError) {
 		res = NewUnauthorizedResponse(err.GetMessage())
 	case http.StatusNotFound:
 		res = NewNotFoundResponse(err.GetMessage())
+	case http.StatusTooManyRequests:
+		if e, ok := err.(interface{ GetRetryAfter() time.Duration }); ok && e.GetRetryAfter() > 0 {
+			s.context.Header(RetryAfterHeader, strconv.Itoa(int(math.Ceil(e.GetRetryAfter().Seconds()))))
+		}
+		res = NewTooManyRequestsResponse(err.GetMessage())
 	case http.StatusInternalServerError:
 		if s.debug {
 			res = NewInternalServerErrorResponse(err.Unwrap().Error())
@@ -105,5 +120,18 @@ func (s *send) sendError(err ApiError) {
 		res = NewInternalServerErrorResponse("An unexpected error occurred. Please try again later.")
 	}
 
+	if r, ok := res.(*response); ok {
+		r.RequestId = GetRequestId(s.context)
+	}
+
 	s.sendResponse(res)
 }
+
+// code of the response sent for the request, empty if the response was not sent by the sender
+func GetResCode(ctx *gin.Context) ResCode {
+	code, _ := ctx.Get(payloadResCode)
+	if resCode, ok := code.(ResCode); ok {
+		return resCode
+	}
+	return ""
+}
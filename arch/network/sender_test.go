@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -53,6 +54,19 @@ func TestSend_MixedError_UnauthorizedError(t *testing.T) {
 	assert.Contains(t, resp.Body.String(), fmt.Sprintf(`"message":"%s"`, "test message"))
 }
 
+func TestSend_MixedError_RetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sender := NewResponseSender()
+	resp := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(resp)
+
+	err := NewTooManyRequestsRetryError("test message", 1500*time.Millisecond, nil)
+	sender.Send(ctx).MixedError(err)
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "2", resp.Header().Get(RetryAfterHeader))
+}
+
 func TestSend_SuccessMsgResponse(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	sender := NewResponseSender()
@@ -85,3 +99,44 @@ func TestSend_SuccessDataResponse(t *testing.T) {
 	assert.Contains(t, resp.Body.String(), fmt.Sprintf(`"message":"%s"`, "test message"))
 	assert.Contains(t, resp.Body.String(), fmt.Sprintf(`"data":%s`, `{"field":"test data"}`))
 }
+
+func TestSend_Error_RequestId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sender := NewResponseSender()
+	resp := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(resp)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	SetRequestId(ctx, "request_id")
+
+	sender.Send(ctx).NotFoundError("test message", nil)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"requestId":"request_id"`)
+}
+
+func TestSend_Success_NoRequestId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sender := NewResponseSender()
+	resp := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(resp)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	SetRequestId(ctx, "request_id")
+
+	sender.Send(ctx).SuccessMsgResponse("success")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, resp.Body.String(), `"requestId"`)
+}
+
+func TestSend_ResCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sender := NewResponseSender()
+	resp := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(resp)
+
+	assert.Equal(t, ResCode(""), GetResCode(ctx))
+
+	sender.Send(ctx).NotFoundError("not found", nil)
+
+	assert.Equal(t, failue_code, GetResCode(ctx))
+}
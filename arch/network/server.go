@@ -0,0 +1,193 @@
+package network
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+type ServerConfig struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	TLSCertPath       string
+	TLSKeyPath        string
+	H2C               bool
+}
+
+type ServerOption func(config *ServerConfig)
+
+func WithReadTimeout(timeout time.Duration) ServerOption {
+	return func(config *ServerConfig) {
+		config.ReadTimeout = timeout
+	}
+}
+
+func WithReadHeaderTimeout(timeout time.Duration) ServerOption {
+	return func(config *ServerConfig) {
+		config.ReadHeaderTimeout = timeout
+	}
+}
+
+func WithWriteTimeout(timeout time.Duration) ServerOption {
+	return func(config *ServerConfig) {
+		config.WriteTimeout = timeout
+	}
+}
+
+func WithIdleTimeout(timeout time.Duration) ServerOption {
+	return func(config *ServerConfig) {
+		config.IdleTimeout = timeout
+	}
+}
+
+func WithMaxHeaderBytes(size int) ServerOption {
+	return func(config *ServerConfig) {
+		config.MaxHeaderBytes = size
+	}
+}
+
+// certificate files are watched and reloaded when they change on the disk
+func WithTLS(certPath string, keyPath string) ServerOption {
+	return func(config *ServerConfig) {
+		config.TLSCertPath = certPath
+		config.TLSKeyPath = keyPath
+	}
+}
+
+// HTTP/2 over cleartext, useful behind a proxy that terminates the TLS
+func WithH2C(enable bool) ServerOption {
+	return func(config *ServerConfig) {
+		config.H2C = enable
+	}
+}
+
+func NewServerConfig(options ...ServerOption) *ServerConfig {
+	config := ServerConfig{}
+	for _, option := range options {
+		option(&config)
+	}
+	return &config
+}
+
+func (c *ServerConfig) TLSEnabled() bool {
+	return len(c.TLSCertPath) > 0 && len(c.TLSKeyPath) > 0
+}
+
+func newHttpServer(address string, handler http.Handler, config *ServerConfig, logger *slog.Logger) (*http.Server, error) {
+	server := &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+
+	if config.TLSEnabled() {
+		reloader, err := newCertReloader(config.TLSCertPath, config.TLSKeyPath, logger)
+		if err != nil {
+			return nil, err
+		}
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+	}
+
+	return server, nil
+}
+
+// the files are checked at most once per interval, not on every handshake
+const certCheckInterval = 10 * time.Second
+
+type certReloader struct {
+	mutex    sync.RWMutex
+	logger   *slog.Logger
+	certPath string
+	keyPath  string
+	interval time.Duration
+	cert     *tls.Certificate
+	// of the last load, a failed one as well, so that a broken file is logged once
+	certModTime time.Time
+	keyModTime  time.Time
+	checkedAt   time.Time
+}
+
+func newCertReloader(certPath string, keyPath string, logger *slog.Logger) (*certReloader, error) {
+	r := certReloader{
+		logger:    logger,
+		certPath:  certPath,
+		keyPath:   keyPath,
+		interval:  certCheckInterval,
+		checkedAt: time.Now(),
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r *certReloader) reload() error {
+	certInfo, err := os.Stat(r.certPath)
+	if err != nil {
+		return err
+	}
+	keyInfo, err := os.Stat(r.keyPath)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	unchanged := !r.certModTime.IsZero() &&
+		certInfo.ModTime().Equal(r.certModTime) &&
+		keyInfo.ModTime().Equal(r.keyModTime)
+	if unchanged {
+		return nil
+	}
+	r.certModTime = certInfo.ModTime()
+	r.keyModTime = keyInfo.ModTime()
+
+	cert, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return fmt.Errorf("could not load tls certificate: %w", err)
+	}
+
+	r.cert = &cert
+	r.logger.Info("tls certificate loaded", "cert", r.certPath)
+	return nil
+}
+
+// only one of the concurrent handshakes checks the files
+func (r *certReloader) due() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.checkedAt) < r.interval {
+		return false
+	}
+	r.checkedAt = now
+	return true
+}
+
+// keeps serving the last good certificate if the new files are broken or half written
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if r.due() {
+		if err := r.reload(); err != nil {
+			r.logger.Error("tls certificate reload failed", "error", err)
+		}
+	}
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.cert, nil
+}
@@ -0,0 +1,155 @@
+package network
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestCert(t *testing.T, dir string, commonName string, modTime time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("could not create certificate: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("could not marshal key: %v", err)
+	}
+
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err := os.WriteFile(certPath, certPem, 0600); err != nil {
+		t.Fatalf("could not write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyPath, keyPem, 0600); err != nil {
+		t.Fatalf("could not write key: %v", err)
+	}
+	os.Chtimes(certPath, modTime, modTime)
+	os.Chtimes(keyPath, modTime, modTime)
+
+	return certPath, keyPath
+}
+
+func TestNewServerConfig(t *testing.T) {
+	config := NewServerConfig(
+		WithReadTimeout(5*time.Second),
+		WithReadHeaderTimeout(2*time.Second),
+		WithWriteTimeout(10*time.Second),
+		WithIdleTimeout(60*time.Second),
+		WithMaxHeaderBytes(4096),
+		WithH2C(true),
+	)
+
+	assert.Equal(t, 5*time.Second, config.ReadTimeout)
+	assert.Equal(t, 2*time.Second, config.ReadHeaderTimeout)
+	assert.Equal(t, 10*time.Second, config.WriteTimeout)
+	assert.Equal(t, 60*time.Second, config.IdleTimeout)
+	assert.Equal(t, 4096, config.MaxHeaderBytes)
+	assert.True(t, config.H2C)
+	assert.False(t, config.TLSEnabled())
+
+	server, err := newHttpServer(":8080", nil, config, slog.Default())
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, server.ReadTimeout)
+	assert.Equal(t, 4096, server.MaxHeaderBytes)
+	assert.Nil(t, server.TLSConfig)
+}
+
+func TestNewServerConfig_TLSMissingFiles(t *testing.T) {
+	config := NewServerConfig(WithTLS("missing/cert.pem", "missing/key.pem"))
+	assert.True(t, config.TLSEnabled())
+
+	_, err := newHttpServer(":8443", nil, config, slog.Default())
+	assert.Error(t, err)
+}
+
+func TestCertReloader_ReloadOnChange(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeTestCert(t, dir, "first", time.Now().Add(-time.Minute))
+
+	reloader, err := newCertReloader(certPath, keyPath, slog.Default())
+	assert.NoError(t, err)
+	reloader.interval = 0
+
+	cert, err := reloader.GetCertificate(nil)
+	assert.NoError(t, err)
+	first, _ := x509.ParseCertificate(cert.Certificate[0])
+	assert.Equal(t, "first", first.Subject.CommonName)
+
+	writeTestCert(t, dir, "second", time.Now())
+
+	cert, err = reloader.GetCertificate(nil)
+	assert.NoError(t, err)
+	second, _ := x509.ParseCertificate(cert.Certificate[0])
+	assert.Equal(t, "second", second.Subject.CommonName)
+}
+
+func TestCertReloader_KeepsLastGoodCert(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeTestCert(t, dir, "good", time.Now().Add(-time.Minute))
+
+	var buf bytes.Buffer
+	reloader, err := newCertReloader(certPath, keyPath, slog.New(slog.NewTextHandler(&buf, nil)))
+	assert.NoError(t, err)
+	reloader.interval = 0
+
+	os.WriteFile(certPath, []byte("broken"), 0600)
+
+	for i := 0; i < 3; i++ {
+		cert, err := reloader.GetCertificate(nil)
+		assert.NoError(t, err)
+		parsed, _ := x509.ParseCertificate(cert.Certificate[0])
+		assert.Equal(t, "good", parsed.Subject.CommonName)
+	}
+
+	// the broken file is logged once, not on every handshake
+	assert.Equal(t, 1, strings.Count(buf.String(), "tls certificate reload failed"))
+}
+
+func TestCertReloader_CheckInterval(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeTestCert(t, dir, "first", time.Now().Add(-time.Minute))
+
+	reloader, err := newCertReloader(certPath, keyPath, slog.Default())
+	assert.NoError(t, err)
+
+	writeTestCert(t, dir, "second", time.Now())
+
+	cert, err := reloader.GetCertificate(nil)
+	assert.NoError(t, err)
+	parsed, _ := x509.ParseCertificate(cert.Certificate[0])
+	assert.Equal(t, "first", parsed.Subject.CommonName)
+
+	reloader.checkedAt = time.Now().Add(-certCheckInterval)
+
+	cert, err = reloader.GetCertificate(nil)
+	assert.NoError(t, err)
+	parsed, _ = x509.ParseCertificate(cert.Certificate[0])
+	assert.Equal(t, "second", parsed.Subject.CommonName)
+}
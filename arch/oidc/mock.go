@@ -0,0 +1,199 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/unusualcodeorg/goserve/arch/jwk"
+)
+
+// a local openid provider for the tests, every authorization is granted to its identity
+type StubServer struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+	Identity     Identity
+	key          *rsa.PrivateKey
+	kid          string
+	mutex        sync.Mutex
+	grants       map[string]url.Values
+}
+
+func NewStubServer(clientID string, clientSecret string, identity Identity) *StubServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	kid, err := jwk.Thumbprint(&key.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	s := &StubServer{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Identity:     identity,
+		key:          key,
+		kid:          kid,
+		grants:       make(map[string]url.Values),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(DiscoveryPath, s.discovery)
+	mux.HandleFunc("/jwks", s.jwks)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+func (s *StubServer) Config(name string, redirectURL string) *Config {
+	return &Config{
+		Name:         name,
+		Issuer:       s.URL,
+		ClientID:     s.ClientID,
+		ClientSecret: s.ClientSecret,
+		RedirectURL:  redirectURL,
+	}
+}
+
+// follows the authorization url like a browser and returns the code of the redirect
+func (s *StubServer) Authorize(authURL string) (code string, state string, err error) {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	res.Body.Close()
+
+	location, err := res.Location()
+	if err != nil {
+		return "", "", err
+	}
+	return location.Query().Get("code"), location.Query().Get("state"), nil
+}
+
+// signs an id token as the stub, for the token responses of the tests
+func (s *StubServer) IDToken(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.kid
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+func (s *StubServer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *StubServer) jwks(w http.ResponseWriter, r *http.Request) {
+	key, err := jwk.NewKey(&s.key.PublicKey, "RS256")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, jwk.Set{Keys: []jwk.Key{*key}})
+}
+
+func (s *StubServer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != s.ClientID || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	code, err := RandomToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.mutex.Lock()
+	s.grants[code] = query
+	s.mutex.Unlock()
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *StubServer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	id, secret, _ := r.BasicAuth()
+	id, _ = url.QueryUnescape(id)
+	secret, _ = url.QueryUnescape(secret)
+	if id != s.ClientID || secret != s.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	// the codes are single use
+	s.mutex.Lock()
+	grant, ok := s.grants[r.PostForm.Get("code")]
+	delete(s.grants, r.PostForm.Get("code"))
+	s.mutex.Unlock()
+
+	valid := ok &&
+		r.PostForm.Get("grant_type") == "authorization_code" &&
+		r.PostForm.Get("redirect_uri") == grant.Get("redirect_uri") &&
+		CodeChallenge(r.PostForm.Get("code_verifier")) == grant.Get("code_challenge")
+	if !valid {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	idToken := s.IDToken(jwt.MapClaims{
+		"iss":            s.URL,
+		"sub":            s.Identity.Subject,
+		"aud":            s.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+		"nonce":          grant.Get("nonce"),
+		"email":          s.Identity.Email,
+		"email_verified": s.Identity.EmailVerified,
+		"name":           s.Identity.Name,
+		"picture":        s.Identity.Picture,
+	})
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "stub",
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
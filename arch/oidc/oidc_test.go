@@ -0,0 +1,193 @@
+package oidc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectURL = "http://localhost:3000/oidc/callback"
+
+func stubIdentity() Identity {
+	return Identity{
+		Subject:       "stub-user",
+		Email:         "stub@abc.com",
+		EmailVerified: true,
+		Name:          "stub user",
+	}
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	stub := NewStubServer("client", "secret", stubIdentity())
+	defer stub.Close()
+
+	p := NewProvider(stub.Config("stub", redirectURL), nil)
+
+	authURL, err := p.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	assert.NoError(t, err)
+
+	u, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, stub.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "client", u.Query().Get("client_id"))
+	assert.Equal(t, redirectURL, u.Query().Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", u.Query().Get("scope"))
+	assert.Equal(t, "state", u.Query().Get("state"))
+	assert.Equal(t, "nonce", u.Query().Get("nonce"))
+	assert.Equal(t, CodeChallenge("verifier"), u.Query().Get("code_challenge"))
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	stub := NewStubServer("client", "secret", stubIdentity())
+	defer stub.Close()
+
+	p := NewProvider(stub.Config("stub", redirectURL), nil)
+	ctx := context.Background()
+
+	verifier, err := RandomToken()
+	assert.NoError(t, err)
+
+	authURL, err := p.AuthCodeURL(ctx, "state", "nonce", verifier)
+	assert.NoError(t, err)
+
+	code, state, err := stub.Authorize(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "state", state)
+
+	identity, err := p.Exchange(ctx, code, verifier, "nonce")
+	assert.NoError(t, err)
+	assert.Equal(t, &Identity{
+		Provider:      "stub",
+		Subject:       "stub-user",
+		Email:         "stub@abc.com",
+		EmailVerified: true,
+		Name:          "stub user",
+	}, identity)
+
+	// the code is single use
+	_, err = p.Exchange(ctx, code, verifier, "nonce")
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestProvider_ExchangeWrongVerifier(t *testing.T) {
+	stub := NewStubServer("client", "secret", stubIdentity())
+	defer stub.Close()
+
+	p := NewProvider(stub.Config("stub", redirectURL), nil)
+	ctx := context.Background()
+
+	authURL, _ := p.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	code, _, err := stub.Authorize(authURL)
+	assert.NoError(t, err)
+
+	_, err = p.Exchange(ctx, code, "other", "nonce")
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestProvider_ExchangeWrongNonce(t *testing.T) {
+	stub := NewStubServer("client", "secret", stubIdentity())
+	defer stub.Close()
+
+	p := NewProvider(stub.Config("stub", redirectURL), nil)
+	ctx := context.Background()
+
+	authURL, _ := p.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	code, _, err := stub.Authorize(authURL)
+	assert.NoError(t, err)
+
+	_, err = p.Exchange(ctx, code, "verifier", "other")
+	assert.ErrorIs(t, err, ErrNonceMismatch)
+}
+
+func TestProvider_ExchangeWrongSecret(t *testing.T) {
+	stub := NewStubServer("client", "secret", stubIdentity())
+	defer stub.Close()
+
+	config := stub.Config("stub", redirectURL)
+	config.ClientSecret = "wrong"
+	p := NewProvider(config, nil)
+	ctx := context.Background()
+
+	authURL, _ := p.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	code, _, err := stub.Authorize(authURL)
+	assert.NoError(t, err)
+
+	_, err = p.Exchange(ctx, code, "verifier", "nonce")
+	assert.ErrorContains(t, err, "invalid_client")
+}
+
+func TestProvider_Verify(t *testing.T) {
+	stub := NewStubServer("client", "secret", stubIdentity())
+	defer stub.Close()
+
+	p := NewProvider(stub.Config("stub", redirectURL), nil).(*provider)
+	ctx := context.Background()
+	meta, err := p.discover(ctx)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": stub.URL,
+			"sub": "stub-user",
+			"aud": "client",
+			"iat": now.Unix(),
+			"exp": now.Add(time.Minute).Unix(),
+		}
+	}
+
+	_, err = p.verify(ctx, meta, stub.IDToken(valid()))
+	assert.NoError(t, err)
+
+	claims := valid()
+	claims["aud"] = "other"
+	_, err = p.verify(ctx, meta, stub.IDToken(claims))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+
+	claims = valid()
+	claims["iss"] = "https://other.example.com"
+	_, err = p.verify(ctx, meta, stub.IDToken(claims))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+
+	claims = valid()
+	claims["exp"] = now.Add(-time.Minute).Unix()
+	_, err = p.verify(ctx, meta, stub.IDToken(claims))
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+
+	claims = valid()
+	claims["azp"] = "other"
+	_, err = p.verify(ctx, meta, stub.IDToken(claims))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+
+	// signed by an other key than the published one
+	other := NewStubServer("client", "secret", stubIdentity())
+	defer other.Close()
+	_, err = p.verify(ctx, meta, other.IDToken(valid()))
+	assert.Error(t, err)
+
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, valid()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	_, err = p.verify(ctx, meta, unsigned)
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+func TestProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	stub := NewStubServer("client", "secret", stubIdentity())
+	defer stub.Close()
+
+	config := stub.Config("stub", redirectURL)
+	config.Issuer = stub.URL + "/tenant"
+	p := NewProvider(config, nil)
+
+	_, err := p.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	assert.Error(t, err)
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// rfc 7636 appendix b
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
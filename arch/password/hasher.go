@@ -0,0 +1,100 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+	ErrMalformedHash    = errors.New("password hash is malformed")
+	// bcrypt uses only the first 72 bytes
+	ErrPasswordTooLong = errors.New("password is too long for the hash algorithm")
+)
+
+type Config struct {
+	Algorithm  string
+	BcryptCost int
+	// memory in KiB
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+// the hashes are encoded with their algorithm and parameters,
+// so the hashes of the other algorithms and the older parameters are verified as well
+type Hasher interface {
+	Hash(password string) (string, error)
+	Verify(password string, encoded string) (bool, error)
+	// the hash is of another algorithm or of other parameters than the configured ones
+	NeedsRehash(encoded string) bool
+}
+
+type hasher struct {
+	config Config
+}
+
+func NewHasher(config Config) (Hasher, error) {
+	switch config.Algorithm {
+	case AlgorithmArgon2id:
+		if config.Argon2Iterations < 1 || config.Argon2Parallelism < 1 || config.Argon2Memory < 8*uint32(config.Argon2Parallelism) {
+			return nil, errors.New("invalid argon2id parameters")
+		}
+	case AlgorithmBcrypt:
+		if err := checkBcryptCost(config.BcryptCost); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, config.Algorithm)
+	}
+	return &hasher{config: config}, nil
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	if h.config.Algorithm == AlgorithmBcrypt {
+		return hashBcrypt(password, h.config.BcryptCost)
+	}
+	return hashArgon2id(password, newArgon2Params(h.config))
+}
+
+func (h *hasher) Verify(password string, encoded string) (bool, error) {
+	switch algorithm(encoded) {
+	case AlgorithmArgon2id:
+		return verifyArgon2id(password, encoded)
+	case AlgorithmBcrypt:
+		return verifyBcrypt(password, encoded)
+	default:
+		return false, ErrUnknownAlgorithm
+	}
+}
+
+func (h *hasher) NeedsRehash(encoded string) bool {
+	if algorithm(encoded) != h.config.Algorithm {
+		return true
+	}
+
+	if h.config.Algorithm == AlgorithmBcrypt {
+		cost, err := bcryptCost(encoded)
+		return err != nil || cost != h.config.BcryptCost
+	}
+
+	params, err := decodeArgon2Params(encoded)
+	return err != nil || *params != *newArgon2Params(h.config)
+}
+
+func algorithm(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return AlgorithmArgon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return AlgorithmBcrypt
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,113 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func argon2Config() Config {
+	return Config{
+		Algorithm:         AlgorithmArgon2id,
+		BcryptCost:        bcrypt.MinCost,
+		Argon2Memory:      64,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+	}
+}
+
+func TestHasher_Argon2id(t *testing.T) {
+	h, err := NewHasher(argon2Config())
+	assert.NoError(t, err)
+
+	hash, err := h.Hash("correct horse")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"))
+
+	ok, err := h.Verify("correct horse", hash)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = h.Verify("wrong horse", hash)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	other, _ := h.Hash("correct horse")
+	assert.NotEqual(t, hash, other, "salted")
+	assert.False(t, h.NeedsRehash(hash))
+}
+
+func TestHasher_Bcrypt(t *testing.T) {
+	config := argon2Config()
+	config.Algorithm = AlgorithmBcrypt
+	h, err := NewHasher(config)
+	assert.NoError(t, err)
+
+	hash, err := h.Hash("correct horse")
+	assert.NoError(t, err)
+
+	ok, err := h.Verify("correct horse", hash)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = h.Verify("wrong horse", hash)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, h.NeedsRehash(hash))
+
+	_, err = h.Hash(strings.Repeat("a", 73))
+	assert.ErrorIs(t, err, ErrPasswordTooLong)
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse"), 5)
+	assert.NoError(t, err)
+
+	h, _ := NewHasher(argon2Config())
+
+	// the other algorithms are still verified
+	ok, err := h.Verify("correct horse", string(legacy))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, h.NeedsRehash(string(legacy)))
+
+	stronger := argon2Config()
+	stronger.Argon2Iterations = 2
+	h2, _ := NewHasher(stronger)
+
+	hash, _ := h.Hash("correct horse")
+	assert.True(t, h2.NeedsRehash(hash))
+
+	ok, err = h2.Verify("correct horse", hash)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	bcryptConfig := argon2Config()
+	bcryptConfig.Algorithm = AlgorithmBcrypt
+	bcryptConfig.BcryptCost = 6
+	h3, _ := NewHasher(bcryptConfig)
+	assert.True(t, h3.NeedsRehash(string(legacy)))
+	assert.True(t, h3.NeedsRehash(hash))
+}
+
+func TestHasher_Invalid(t *testing.T) {
+	_, err := NewHasher(Config{Algorithm: "md5"})
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+
+	_, err = NewHasher(Config{Algorithm: AlgorithmBcrypt, BcryptCost: 100})
+	assert.Error(t, err)
+
+	_, err = NewHasher(Config{Algorithm: AlgorithmArgon2id})
+	assert.Error(t, err)
+
+	h, _ := NewHasher(argon2Config())
+
+	_, err = h.Verify("correct horse", "plaintext")
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+
+	_, err = h.Verify("correct horse", "$argon2id$v=19$m=64,t=1,p=1$!!$!!")
+	assert.ErrorIs(t, err, ErrMalformedHash)
+	assert.True(t, h.NeedsRehash("$argon2id$v=19$broken"))
+}
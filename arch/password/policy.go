@@ -0,0 +1,90 @@
+package password
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrBreachedPassword = errors.New("password is too common, it is found in the breached passwords")
+
+type PolicyConfig struct {
+	MinLength int
+	MaxLength int
+	// one password per line, the lines starting with # are skipped. empty disables the check
+	BreachedListPath string
+}
+
+type Policy interface {
+	Validate(password string) error
+}
+
+type policy struct {
+	minLength int
+	maxLength int
+	breached  map[string]struct{}
+}
+
+func NewPolicy(config PolicyConfig) (Policy, error) {
+	if config.MaxLength > 0 && config.MaxLength < config.MinLength {
+		return nil, errors.New("password max length is less than the min length")
+	}
+
+	breached, err := loadBreachedList(config.BreachedListPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy{
+		minLength: config.MinLength,
+		maxLength: config.MaxLength,
+		breached:  breached,
+	}, nil
+}
+
+// the length is counted in characters, the breached passwords are matched ignoring the case
+func (p *policy) Validate(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.minLength {
+		return fmt.Errorf("password must be at least %d characters", p.minLength)
+	}
+	if p.maxLength > 0 && length > p.maxLength {
+		return fmt.Errorf("password must be at most %d characters", p.maxLength)
+	}
+
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		return ErrBreachedPassword
+	}
+
+	return nil
+}
+
+func loadBreachedList(path string) (map[string]struct{}, error) {
+	breached := make(map[string]struct{})
+	if path == "" {
+		return breached, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		breached[strings.ToLower(line)] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return breached, nil
+}
@@ -0,0 +1,39 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	err := os.WriteFile(path, []byte("# common passwords\npassword1\n\nQwerty123\n"), 0o600)
+	assert.NoError(t, err)
+
+	p, err := NewPolicy(PolicyConfig{MinLength: 8, MaxLength: 12, BreachedListPath: path})
+	assert.NoError(t, err)
+
+	assert.EqualError(t, p.Validate("short"), "password must be at least 8 characters")
+	assert.EqualError(t, p.Validate("much-too-long-password"), "password must be at most 12 characters")
+	assert.ErrorIs(t, p.Validate("Password1"), ErrBreachedPassword)
+	assert.ErrorIs(t, p.Validate("qwerty123"), ErrBreachedPassword)
+	assert.NoError(t, p.Validate("unusual-pwd"))
+
+	// the characters are counted, not the bytes
+	assert.NoError(t, p.Validate("пароль-пар"))
+}
+
+func TestPolicy_Config(t *testing.T) {
+	p, err := NewPolicy(PolicyConfig{MinLength: 6})
+	assert.NoError(t, err)
+	assert.NoError(t, p.Validate("password1"))
+
+	_, err = NewPolicy(PolicyConfig{MinLength: 8, MaxLength: 6})
+	assert.Error(t, err)
+
+	_, err = NewPolicy(PolicyConfig{BreachedListPath: "missing.txt"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryLimiter struct {
+	mutex   sync.Mutex
+	entries map[string][]time.Time
+	now     func() time.Time
+}
+
+// counters live in the process, so it is meant for the tests and the single instance setups
+func NewMemoryLimiter() Limiter {
+	return &memoryLimiter{
+		entries: make(map[string][]time.Time),
+		now:     time.Now,
+	}
+}
+
+func (l *memoryLimiter) Allow(ctx context.Context, key string, requests int, window time.Duration) (*Result, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	start := now.Add(-window)
+
+	entries := l.entries[key]
+	i := 0
+	for i < len(entries) && !entries[i].After(start) {
+		i++
+	}
+	entries = entries[i:]
+
+	result := Result{Limit: requests}
+	if len(entries) < requests {
+		entries = append(entries, now)
+		result.Allowed = true
+	}
+	result.Remaining = requests - len(entries)
+	if len(entries) > 0 {
+		result.Reset = entries[0].Add(window).Sub(now)
+	}
+
+	if len(entries) == 0 {
+		delete(l.entries, key)
+	} else {
+		l.entries[key] = entries
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	l := NewMemoryLimiter()
+
+	for i := 0; i < 3; i++ {
+		res, err := l.Allow(context.Background(), "ip:127.0.0.1", 3, time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 2-i, res.Remaining)
+	}
+
+	res, err := l.Allow(context.Background(), "ip:127.0.0.1", 3, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, 3, res.Limit)
+	assert.Greater(t, res.Reset, time.Duration(0))
+
+	res, err = l.Allow(context.Background(), "ip:127.0.0.2", 3, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
+
+func TestMemoryLimiter_SlidingWindow(t *testing.T) {
+	now := time.Now()
+	l := NewMemoryLimiter().(*memoryLimiter)
+	l.now = func() time.Time { return now }
+
+	l.Allow(context.Background(), "key", 2, time.Minute)
+	now = now.Add(30 * time.Second)
+	l.Allow(context.Background(), "key", 2, time.Minute)
+
+	res, _ := l.Allow(context.Background(), "key", 2, time.Minute)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 30*time.Second, res.Reset)
+
+	now = now.Add(31 * time.Second)
+	res, _ = l.Allow(context.Background(), "key", 2, time.Minute)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, 29*time.Second, res.Reset)
+}
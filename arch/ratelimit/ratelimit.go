@@ -0,0 +1,19 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// time till the oldest counted request leaves the window
+	Reset time.Duration
+}
+
+// sliding window limiter, every allowed request is counted for the window duration
+type Limiter interface {
+	Allow(ctx context.Context, key string, requests int, window time.Duration) (*Result, error)
+}
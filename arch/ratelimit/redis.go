@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/unusualcodeorg/goserve/arch/redis"
+)
+
+const keyPrefix = "ratelimit:"
+
+// sorted set of the request timestamps, the check and the insert are atomic
+// returns {allowed, count, oldest}
+var slidingWindow = goredis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	count = count + 1
+	allowed = 1
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local first = now
+if oldest[2] then
+	first = tonumber(oldest[2])
+end
+return {allowed, count, first}
+`)
+
+type redisLimiter struct {
+	store redis.Store
+}
+
+// counters are shared by all the instances connected to the store
+func NewRedisLimiter(store redis.Store) Limiter {
+	return &redisLimiter{
+		store: store,
+	}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, requests int, window time.Duration) (*Result, error) {
+	now := time.Now().UnixMilli()
+	member, err := newMember()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := slidingWindow.Run(ctx, l.store.GetInstance().Client,
+		[]string{keyPrefix + key}, now, window.Milliseconds(), requests, member,
+	).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	reset := time.Duration(values[2]+window.Milliseconds()-now) * time.Millisecond
+	return &Result{
+		Allowed:   values[0] == 1,
+		Limit:     requests,
+		Remaining: requests - int(values[1]),
+		Reset:     reset,
+	}, nil
+}
+
+// requests in the same millisecond need distinct members
+func newMember() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
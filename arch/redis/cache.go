@@ -3,14 +3,21 @@ package redis
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 type Cache[T any] interface {
+	WithContext(ctx context.Context) Cache[T]
 	SetJSON(key string, value *T, expiration time.Duration) error
+	SetJSONNX(key string, value *T, expiration time.Duration) (bool, error)
 	GetJSON(key string) (*T, error)
+	PopJSON(key string) (*T, error)
 	SetJSONList(key string, values []*T, expiration time.Duration) error
 	GetJSONList(key string) ([]*T, error)
+	Delete(keys ...string) error
 }
 
 type cache[T any] struct {
@@ -18,6 +25,11 @@ type cache[T any] struct {
 	store   Store
 }
 
+// the key does not exist in the cache
+func IsNil(err error) bool {
+	return errors.Is(err, redis.Nil)
+}
+
 func NewCache[T any](store Store) Cache[T] {
 	return &cache[T]{
 		context: context.Background(),
@@ -25,17 +37,63 @@ func NewCache[T any](store Store) Cache[T] {
 	}
 }
 
+// returns a copy of the cache whose calls are part of the request trace
+func (c *cache[T]) WithContext(ctx context.Context) Cache[T] {
+	return &cache[T]{
+		context: ctx,
+		store:   c.store,
+	}
+}
+
 func (c *cache[T]) SetJSON(key string, value *T, expiration time.Duration) error {
 	data, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
 
-	return c.store.GetInstance().Set(c.context, key, data, expiration).Err()
+	ctx, span := startSpan(c.context, "set", key)
+	err = c.store.GetInstance().Set(ctx, key, data, expiration).Err()
+	endSpan(span, err)
+	return err
+}
+
+// sets the value only if the key does not exist, so that only one caller can claim it
+func (c *cache[T]) SetJSONNX(key string, value *T, expiration time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, span := startSpan(c.context, "setnx", key)
+	set, err := c.store.GetInstance().SetNX(ctx, key, data, expiration).Result()
+	endSpan(span, err)
+	return set, err
 }
 
 func (c *cache[T]) GetJSON(key string) (*T, error) {
-	data, err := c.store.GetInstance().Get(c.context, key).Bytes()
+	ctx, span := startSpan(c.context, "get", key)
+	data, err := c.store.GetInstance().Get(ctx, key).Bytes()
+	endSpan(span, err)
+	recordLookup("GetJSON", err)
+	if err != nil {
+		return nil, err
+	}
+
+	var dest T
+	err = json.Unmarshal(data, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+// gets and deletes the value at once, so that only one caller can get it
+func (c *cache[T]) PopJSON(key string) (*T, error) {
+	ctx, span := startSpan(c.context, "getdel", key)
+	data, err := c.store.GetInstance().GetDel(ctx, key).Bytes()
+	endSpan(span, err)
+	recordLookup("PopJSON", err)
 	if err != nil {
 		return nil, err
 	}
@@ -64,11 +122,17 @@ func (c *cache[T]) SetJSONList(key string, values []*T, expiration time.Duration
 		return err
 	}
 
-	return c.store.GetInstance().Set(c.context, key, str, expiration).Err()
+	ctx, span := startSpan(c.context, "set", key)
+	err = c.store.GetInstance().Set(ctx, key, str, expiration).Err()
+	endSpan(span, err)
+	return err
 }
 
 func (c *cache[T]) GetJSONList(key string) ([]*T, error) {
-	str, err := c.store.GetInstance().Get(c.context, key).Result()
+	ctx, span := startSpan(c.context, "get", key)
+	str, err := c.store.GetInstance().Get(ctx, key).Result()
+	endSpan(span, err)
+	recordLookup("GetJSONList", err)
 	if err != nil {
 		return nil, err
 	}
@@ -87,3 +151,13 @@ func (c *cache[T]) GetJSONList(key string) ([]*T, error) {
 
 	return dest, nil
 }
+
+func (c *cache[T]) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	ctx, span := startSpan(c.context, "del", keys[0])
+	err := c.store.GetInstance().Del(ctx, keys...).Err()
+	endSpan(span, err)
+	return err
+}
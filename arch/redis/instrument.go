@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/unusualcodeorg/goserve/arch/redis"
+
+var cacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "redis_cache_lookups_total",
+	Help: "Count of the cache lookups by result.",
+}, []string{"operation", "result"})
+
+func startSpan(ctx context.Context, operation string, key string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "redis."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemRedis,
+			semconv.DBOperationName(operation),
+			attribute.String("db.redis.key_prefix", keyPrefix(key)),
+		),
+	)
+}
+
+// the keys carry the state, token ids and emails, so only their namespace is recorded
+func keyPrefix(key string) string {
+	i := strings.IndexAny(key, "_:")
+	if i < 0 {
+		return "?"
+	}
+	return key[:i+1] + "?"
+}
+
+// a cache miss is not an error for the span
+func endSpan(span trace.Span, err error) {
+	if err == redis.Nil {
+		span.SetAttributes(attribute.Bool("cache.hit", false))
+	} else if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func recordLookup(operation string, err error) {
+	result := "hit"
+	if err == redis.Nil {
+		result = "miss"
+	} else if err != nil {
+		result = "error"
+	}
+	cacheLookups.WithLabelValues(operation, result).Inc()
+}
@@ -0,0 +1,24 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyPrefix(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"oidc_state_abc", "oidc_?"},
+		{"revoked_token_abc", "revoked_?"},
+		{"signin:account:a@b.com", "signin:?"},
+		{"secret", "?"},
+		{"", "?"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, keyPrefix(tt.key))
+	}
+}
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCache[T any] struct {
+	mock.Mock
+}
+
+func (m *MockCache[T]) WithContext(ctx context.Context) Cache[T] {
+	args := m.Called(ctx)
+	return args.Get(0).(Cache[T])
+}
+
+func (m *MockCache[T]) SetJSON(key string, value *T, expiration time.Duration) error {
+	args := m.Called(key, value, expiration)
+	return args.Error(0)
+}
+
+func (m *MockCache[T]) SetJSONNX(key string, value *T, expiration time.Duration) (bool, error) {
+	args := m.Called(key, value, expiration)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCache[T]) GetJSON(key string) (*T, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*T), args.Error(1)
+}
+
+func (m *MockCache[T]) PopJSON(key string) (*T, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*T), args.Error(1)
+}
+
+func (m *MockCache[T]) SetJSONList(key string, values []*T, expiration time.Duration) error {
+	args := m.Called(key, values, expiration)
+	return args.Error(0)
+}
+
+func (m *MockCache[T]) GetJSONList(key string) ([]*T, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*T), args.Error(1)
+}
+
+func (m *MockCache[T]) Delete(keys ...string) error {
+	args := m.Called(keys)
+	return args.Error(0)
+}
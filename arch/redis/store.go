@@ -3,9 +3,10 @@ package redis
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/unusualcodeorg/goserve/arch/health"
 )
 
 type Config struct {
@@ -19,14 +20,16 @@ type Store interface {
 	GetInstance() *store
 	Connect()
 	Disconnect()
+	RegisterHealthCheck(registry health.Registry)
 }
 
 type store struct {
 	*redis.Client
 	context context.Context
+	logger  *slog.Logger
 }
 
-func NewStore(context context.Context, config *Config) Store {
+func NewStore(context context.Context, config *Config, logger *slog.Logger) Store {
 	client := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", config.Host, config.Port),
 		Password: config.Pwd,
@@ -35,6 +38,7 @@ func NewStore(context context.Context, config *Config) Store {
 	return &store{
 		context: context,
 		Client:  client,
+		logger:  logger.With("component", "redis"),
 	}
 }
 
@@ -43,19 +47,28 @@ func (r *store) GetInstance() *store {
 }
 
 func (r *store) Connect() {
-	fmt.Println("connecting to redis")
+	r.logger.Info("connecting to redis")
 	pong, err := r.Ping(r.context).Result()
 	if err != nil {
+		r.logger.Error("could not connect to redis", "error", err)
 		panic(fmt.Errorf("could not connect to redis: %v", err))
 	}
-	fmt.Println("connected to Redis:", pong)
+	r.logger.Info("connected to redis", "ping", pong)
 }
 
+// runs as a shutdown hook, a failure is only logged so that the other hooks still run
 func (r *store) Disconnect() {
-	fmt.Println("disconnecting redis...")
+	r.logger.Info("disconnecting redis")
 	err := r.Close()
 	if err != nil {
-		log.Panic(err)
+		r.logger.Error("disconnecting redis failed", "error", err)
+		return
 	}
-	fmt.Println("disconnected redis")
+	r.logger.Info("disconnected redis")
+}
+
+func (r *store) RegisterHealthCheck(registry health.Registry) {
+	registry.Register("redis", func(ctx context.Context) error {
+		return r.Ping(ctx).Err()
+	})
 }
@@ -0,0 +1,60 @@
+package totp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the sha1 seed of rfc 6238 appendix b
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerate_RFC6238(t *testing.T) {
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := Generate(rfcSecret, time.Unix(unix, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	now := time.Unix(1700000000, 0)
+	code, err := Generate(secret, now)
+	assert.NoError(t, err)
+
+	counter, ok := Validate(secret, code, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, Counter(now), counter)
+
+	// the previous period is accepted within the skew
+	counter, ok = Validate(secret, code, now.Add(Period), 1)
+	assert.True(t, ok)
+	assert.Equal(t, Counter(now), counter)
+
+	_, ok = Validate(secret, code, now.Add(2*Period), 1)
+	assert.False(t, ok)
+
+	_, ok = Validate(secret, "12345", now, 1)
+	assert.False(t, ok)
+
+	_, ok = Validate("not base32!", code, now, 1)
+	assert.False(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("goserve", "test@abc.com", "JBSWY3DPEHPK3PXP")
+	assert.Equal(t, "otpauth://totp/goserve:test@abc.com?algorithm=SHA1&digits=6&issuer=goserve&period=30&secret=JBSWY3DPEHPK3PXP", uri)
+}
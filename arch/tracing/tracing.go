@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+type Config struct {
+	Exporter     string
+	FilePath     string
+	OTLPEndpoint string
+	ServiceName  string
+	SampleRatio  float64
+}
+
+type Provider interface {
+	Enabled() bool
+	Shutdown()
+}
+
+type provider struct {
+	tracerProvider *sdktrace.TracerProvider
+	closer         io.Closer
+	logger         *slog.Logger
+}
+
+// the otel globals remain no-op when the exporter is none, so the instrumentation costs nothing
+func NewProvider(config *Config, logger *slog.Logger) (Provider, error) {
+	p := provider{
+		logger: logger.With("component", "tracing"),
+	}
+
+	exporter, closer, err := newExporter(config)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return &p, nil
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(config.ServiceName)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := config.SampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+
+	p.closer = closer
+	p.tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+
+	otel.SetTracerProvider(p.tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	p.logger.Info("tracing enabled", "exporter", config.Exporter)
+	return &p, nil
+}
+
+func newExporter(config *Config) (sdktrace.SpanExporter, io.Closer, error) {
+	switch strings.ToLower(config.Exporter) {
+	case "", ExporterNone:
+		return nil, nil, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, nil, err
+	case ExporterFile:
+		file, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		return exporter, file, err
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(
+			context.Background(),
+			otlptracehttp.WithEndpointURL(config.OTLPEndpoint),
+		)
+		return exporter, nil, err
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter: %s", config.Exporter)
+	}
+}
+
+func (p *provider) Enabled() bool {
+	return p.tracerProvider != nil
+}
+
+// flushes the pending spans, should run after the server has drained the requests
+func (p *provider) Shutdown() {
+	if p.tracerProvider == nil {
+		return
+	}
+	if err := p.tracerProvider.Shutdown(context.Background()); err != nil {
+		p.logger.Error("tracing shutdown failed", "error", err)
+	}
+	if p.closer != nil {
+		p.closer.Close()
+	}
+}
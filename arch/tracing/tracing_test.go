@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/arch/logger"
+	"go.opentelemetry.io/otel"
+)
+
+func TestNewProvider_None(t *testing.T) {
+	p, err := NewProvider(&Config{Exporter: ExporterNone}, logger.NewDiscardLogger())
+	assert.NoError(t, err)
+	assert.False(t, p.Enabled())
+	p.Shutdown()
+}
+
+func TestNewProvider_Unknown(t *testing.T) {
+	_, err := NewProvider(&Config{Exporter: "zipkin"}, logger.NewDiscardLogger())
+	assert.Error(t, err)
+}
+
+func TestNewProvider_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	config := &Config{
+		Exporter:    ExporterFile,
+		FilePath:    path,
+		ServiceName: "goserve-test",
+	}
+
+	p, err := NewProvider(config, logger.NewDiscardLogger())
+	assert.NoError(t, err)
+	assert.True(t, p.Enabled())
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	span.End()
+
+	p.Shutdown()
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"test-span"`)
+	assert.Contains(t, string(data), `goserve-test`)
+}
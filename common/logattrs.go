@@ -0,0 +1,23 @@
+package common
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// api key and user of the request if they were resolved by the auth middlewares
+func ContextLogAttrs(ctx *gin.Context) []slog.Attr {
+	payload := NewContextPayload()
+	var attrs []slog.Attr
+
+	if apikey := payload.GetApiKey(ctx); apikey != nil {
+		attrs = append(attrs, slog.String("apiKeyId", apikey.ID.Hex()))
+	}
+
+	if user := payload.GetUser(ctx); user != nil {
+		attrs = append(attrs, slog.String("userId", user.ID.Hex()))
+	}
+
+	return attrs
+}
@@ -17,10 +17,13 @@ const (
 type ContextPayload interface {
 	SetApiKey(ctx *gin.Context, value *authModel.ApiKey)
 	MustGetApiKey(ctx *gin.Context) *authModel.ApiKey
+	GetApiKey(ctx *gin.Context) *authModel.ApiKey
 	SetUser(ctx *gin.Context, value *userModel.User)
 	MustGetUser(ctx *gin.Context) *userModel.User
+	GetUser(ctx *gin.Context) *userModel.User
 	SetKeystore(ctx *gin.Context, value *authModel.Keystore)
 	MustGetKeystore(ctx *gin.Context) *authModel.Keystore
+	GetKeystore(ctx *gin.Context) *authModel.Keystore
 }
 
 type payload struct{}
@@ -41,6 +44,12 @@ func (u *payload) MustGetApiKey(ctx *gin.Context) *authModel.ApiKey {
 	return value
 }
 
+func (u *payload) GetApiKey(ctx *gin.Context) *authModel.ApiKey {
+	value, _ := ctx.Get(payloadApiKey)
+	apikey, _ := value.(*authModel.ApiKey)
+	return apikey
+}
+
 func (u *payload) SetUser(ctx *gin.Context, value *userModel.User) {
 	ctx.Set(payloadUser, value)
 }
@@ -53,6 +62,12 @@ func (u *payload) MustGetUser(ctx *gin.Context) *userModel.User {
 	return value
 }
 
+func (u *payload) GetUser(ctx *gin.Context) *userModel.User {
+	value, _ := ctx.Get(payloadUser)
+	user, _ := value.(*userModel.User)
+	return user
+}
+
 func (u *payload) SetKeystore(ctx *gin.Context, value *authModel.Keystore) {
 	ctx.Set(payloadKeystore, value)
 }
@@ -64,3 +79,9 @@ func (u *payload) MustGetKeystore(ctx *gin.Context) *authModel.Keystore {
 	}
 	return value
 }
+
+func (u *payload) GetKeystore(ctx *gin.Context) *authModel.Keystore {
+	value, _ := ctx.Get(payloadKeystore)
+	keystore, _ := value.(*authModel.Keystore)
+	return keystore
+}
@@ -0,0 +1,22 @@
+package common
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/arch/network"
+)
+
+// the api key id is used so that the key itself is never written to the store
+func RateLimitByApiKey(ctx *gin.Context) string {
+	if apikey := NewContextPayload().GetApiKey(ctx); apikey != nil {
+		return "apikey:" + apikey.ID.Hex()
+	}
+	return network.RateLimitByIP(ctx)
+}
+
+// must be mounted after the authentication, falls back to the client ip otherwise
+func RateLimitByUser(ctx *gin.Context) string {
+	if user := NewContextPayload().GetUser(ctx); user != nil {
+		return "user:" + user.ID.Hex()
+	}
+	return network.RateLimitByIP(ctx)
+}
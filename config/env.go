@@ -2,15 +2,67 @@ package config
 
 import (
 	"log"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
 type Env struct {
 	// server
-	GoMode     string `mapstructure:"GO_MODE"`
-	ServerHost string `mapstructure:"SERVER_HOST"`
-	ServerPort uint16 `mapstructure:"SERVER_PORT"`
+	GoMode                  string `mapstructure:"GO_MODE"`
+	ServerHost              string `mapstructure:"SERVER_HOST"`
+	ServerPort              uint16 `mapstructure:"SERVER_PORT"`
+	ServerShutdownTimeout   uint16 `mapstructure:"SERVER_SHUTDOWN_TIMEOUT_SEC"`
+	ServerReadTimeout       uint16 `mapstructure:"SERVER_READ_TIMEOUT_SEC"`
+	ServerReadHeaderTimeout uint16 `mapstructure:"SERVER_READ_HEADER_TIMEOUT_SEC"`
+	ServerWriteTimeout      uint16 `mapstructure:"SERVER_WRITE_TIMEOUT_SEC"`
+	ServerIdleTimeout       uint16 `mapstructure:"SERVER_IDLE_TIMEOUT_SEC"`
+	ServerMaxHeaderBytes    int    `mapstructure:"SERVER_MAX_HEADER_BYTES"`
+	ServerH2C               bool   `mapstructure:"SERVER_H2C"`
+	HealthCheckTimeout      uint16 `mapstructure:"HEALTH_CHECK_TIMEOUT_SEC"`
+	// comma separated ips or cidrs of the proxies whose forwarded headers are trusted, empty trusts none
+	TrustedProxyList string   `mapstructure:"TRUSTED_PROXIES"`
+	TrustedProxies   []string `mapstructure:"-"`
+	// log
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+	LogLevel  string `mapstructure:"LOG_LEVEL"`
+	// tracing
+	TracingExporter     string  `mapstructure:"TRACING_EXPORTER"`
+	TracingFilePath     string  `mapstructure:"TRACING_FILE_PATH"`
+	TracingOTLPEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingServiceName  string  `mapstructure:"TRACING_SERVICE_NAME"`
+	TracingSampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	// metrics
+	MetricsPath  string `mapstructure:"METRICS_PATH"`
+	MetricsToken string `mapstructure:"METRICS_TOKEN"`
+	// rate limit
+	RateLimitRequests  int    `mapstructure:"RATE_LIMIT_REQUESTS"`
+	RateLimitWindowSec uint32 `mapstructure:"RATE_LIMIT_WINDOW_SEC"`
+	// auth route limits, per client ip and per user for the email verification and the password change
+	SignInRateLimitRequests          int    `mapstructure:"SIGNIN_RATE_LIMIT_REQUESTS"`
+	SignInRateLimitWindowSec         uint32 `mapstructure:"SIGNIN_RATE_LIMIT_WINDOW_SEC"`
+	VerifyEmailRateLimitRequests     int    `mapstructure:"VERIFY_EMAIL_RATE_LIMIT_REQUESTS"`
+	VerifyEmailRateLimitWindowSec    uint32 `mapstructure:"VERIFY_EMAIL_RATE_LIMIT_WINDOW_SEC"`
+	PasswordResetRateLimitRequests   int    `mapstructure:"PASSWORD_RESET_RATE_LIMIT_REQUESTS"`
+	PasswordResetRateLimitWindowSec  uint32 `mapstructure:"PASSWORD_RESET_RATE_LIMIT_WINDOW_SEC"`
+	UnlockRateLimitRequests          int    `mapstructure:"UNLOCK_RATE_LIMIT_REQUESTS"`
+	UnlockRateLimitWindowSec         uint32 `mapstructure:"UNLOCK_RATE_LIMIT_WINDOW_SEC"`
+	ChangePasswordRateLimitRequests  int    `mapstructure:"CHANGE_PASSWORD_RATE_LIMIT_REQUESTS"`
+	ChangePasswordRateLimitWindowSec uint32 `mapstructure:"CHANGE_PASSWORD_RATE_LIMIT_WINDOW_SEC"`
+	ChangeEmailRateLimitRequests     int    `mapstructure:"CHANGE_EMAIL_RATE_LIMIT_REQUESTS"`
+	ChangeEmailRateLimitWindowSec    uint32 `mapstructure:"CHANGE_EMAIL_RATE_LIMIT_WINDOW_SEC"`
+	ContactRateLimitRequests         int    `mapstructure:"CONTACT_RATE_LIMIT_REQUESTS"`
+	ContactRateLimitWindowSec        uint32 `mapstructure:"CONTACT_RATE_LIMIT_WINDOW_SEC"`
+	// secret of the api key hashes, changing it invalidates all the keys
+	ApiKeyHashSecret string `mapstructure:"APIKEY_HASH_SECRET"`
+	// api key cache, a zero ttl disables the cache
+	ApiKeyCacheTTLSec         uint32 `mapstructure:"APIKEY_CACHE_TTL_SEC"`
+	ApiKeyNegativeCacheTTLSec uint32 `mapstructure:"APIKEY_NEGATIVE_CACHE_TTL_SEC"`
+	ApiKeyLocalCacheSize      int    `mapstructure:"APIKEY_LOCAL_CACHE_SIZE"`
+	ApiKeyLocalCacheTTLSec    uint32 `mapstructure:"APIKEY_LOCAL_CACHE_TTL_SEC"`
+	// tls
+	TLSCertPath string `mapstructure:"TLS_CERT_PATH"`
+	TLSKeyPath  string `mapstructure:"TLS_KEY_PATH"`
 	// database
 	DBHost         string `mapstructure:"DB_HOST"`
 	DBName         string `mapstructure:"DB_NAME"`
@@ -25,14 +77,74 @@ type Env struct {
 	RedisPort uint16 `mapstructure:"REDIS_PORT"`
 	RedisPwd  string `mapstructure:"REDIS_PASSWORD"`
 	RedisDB   int    `mapstructure:"REDIS_DB"`
-	// keys
-	RSAPrivateKeyPath string `mapstructure:"RSA_PRIVATE_KEY_PATH"`
-	RSAPublicKeyPath  string `mapstructure:"RSA_PUBLIC_KEY_PATH"`
+	// keys, all of them must be of the signing alg: RS256, ES256 or EdDSA
+	TokenSigningAlg     string `mapstructure:"TOKEN_SIGNING_ALG"`
+	TokenPrivateKeyPath string `mapstructure:"TOKEN_PRIVATE_KEY_PATH"`
+	TokenPublicKeyPath  string `mapstructure:"TOKEN_PUBLIC_KEY_PATH"`
+	// public keys of the rotated out signing keys, kept to verify their tokens
+	TokenRetiredKeysDir string `mapstructure:"TOKEN_RETIRED_KEYS_DIR"`
 	// Token
 	AccessTokenValiditySec  uint64 `mapstructure:"ACCESS_TOKEN_VALIDITY_SEC"`
 	RefreshTokenValiditySec uint64 `mapstructure:"REFRESH_TOKEN_VALIDITY_SEC"`
 	TokenIssuer             string `mapstructure:"TOKEN_ISSUER"`
 	TokenAudience           string `mapstructure:"TOKEN_AUDIENCE"`
+	// trust the signed claims instead of looking up the user and the keystore on every request
+	TokenStatelessVerification bool `mapstructure:"TOKEN_STATELESS_VERIFICATION"`
+	// session activity, a zero idle timeout disables it
+	SessionIdleTimeoutSec      uint64 `mapstructure:"SESSION_IDLE_TIMEOUT_SEC"`
+	SessionLastUsedThrottleSec uint64 `mapstructure:"SESSION_LAST_USED_THROTTLE_SEC"`
+	// mailer: smtp, file or memory
+	Mailer        string `mapstructure:"MAILER"`
+	MailerFrom    string `mapstructure:"MAILER_FROM"`
+	MailerFileDir string `mapstructure:"MAILER_FILE_DIR"`
+	SMTPHost      string `mapstructure:"SMTP_HOST"`
+	SMTPPort      uint16 `mapstructure:"SMTP_PORT"`
+	SMTPUser      string `mapstructure:"SMTP_USER"`
+	SMTPPwd       string `mapstructure:"SMTP_PASSWORD"`
+	// email verification, the token is appended to the url
+	EmailVerificationURL         string `mapstructure:"EMAIL_VERIFICATION_URL"`
+	EmailVerificationValiditySec uint64 `mapstructure:"EMAIL_VERIFICATION_VALIDITY_SEC"`
+	// password reset, the token is appended to the url
+	PasswordResetURL         string `mapstructure:"PASSWORD_RESET_URL"`
+	PasswordResetValiditySec uint64 `mapstructure:"PASSWORD_RESET_VALIDITY_SEC"`
+	// mfa, the secret key encrypts the stored totp secrets
+	MFAIssuer               string `mapstructure:"MFA_ISSUER"`
+	MFASecretKey            string `mapstructure:"MFA_SECRET_KEY"`
+	MFAChallengeValiditySec uint64 `mapstructure:"MFA_CHALLENGE_VALIDITY_SEC"`
+	// comma separated role codes whose users must sign in with mfa
+	MFARequiredRoles string `mapstructure:"MFA_REQUIRED_ROLES"`
+	// sign in lockout, the failures are counted per account and per ip
+	SignInFreeAttempts       uint16 `mapstructure:"SIGNIN_FREE_ATTEMPTS"`
+	SignInDelayBaseSec       uint64 `mapstructure:"SIGNIN_DELAY_BASE_SEC"`
+	SignInDelayMaxSec        uint64 `mapstructure:"SIGNIN_DELAY_MAX_SEC"`
+	SignInLockoutThreshold   uint16 `mapstructure:"SIGNIN_LOCKOUT_THRESHOLD"`
+	SignInIPLockoutThreshold uint16 `mapstructure:"SIGNIN_IP_LOCKOUT_THRESHOLD"`
+	SignInLockoutSec         uint64 `mapstructure:"SIGNIN_LOCKOUT_SEC"`
+	// the unlock token is appended to the url
+	AccountUnlockURL string `mapstructure:"ACCOUNT_UNLOCK_URL"`
+	// password hash, argon2id or bcrypt. the outdated hashes are replaced on the sign in
+	PasswordHashAlgorithm     string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	PasswordBcryptCost        uint8  `mapstructure:"PASSWORD_BCRYPT_COST"`
+	PasswordArgon2MemoryKiB   uint32 `mapstructure:"PASSWORD_ARGON2_MEMORY_KIB"`
+	PasswordArgon2Iterations  uint32 `mapstructure:"PASSWORD_ARGON2_ITERATIONS"`
+	PasswordArgon2Parallelism uint8  `mapstructure:"PASSWORD_ARGON2_PARALLELISM"`
+	// password policy of the new passwords
+	PasswordMinLength        uint16 `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMaxLength        uint16 `mapstructure:"PASSWORD_MAX_LENGTH"`
+	PasswordBreachedListPath string `mapstructure:"PASSWORD_BREACHED_LIST_PATH"`
+	// comma separated names of the oidc providers, see OIDCProvider
+	OIDCProviderNames string         `mapstructure:"OIDC_PROVIDERS"`
+	OIDCProviders     []OIDCProvider `mapstructure:"-"`
+}
+
+// read from the OIDC_<NAME>_* variables of the provider
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
 func NewEnv(filename string, override bool) *Env {
@@ -53,5 +165,54 @@ func NewEnv(filename string, override bool) *Env {
 		log.Fatal("Error loading environment file", err)
 	}
 
+	env.OIDCProviders = oidcProviders(env.OIDCProviderNames)
+	legacyTokenKeys(&env)
+	env.TrustedProxies = trustedProxies(env.TrustedProxyList)
+
 	return &env
 }
+
+func oidcProviders(names string) []OIDCProvider {
+	var providers []OIDCProvider
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProvider{
+			Name:         name,
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			RedirectURL:  viper.GetString(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(viper.GetString(prefix + "SCOPES")),
+		})
+	}
+	return providers
+}
+
+// the keys were RS256 only and their paths were named RSA_*_KEY_PATH before the other signing algs
+func legacyTokenKeys(env *Env) {
+	if env.TokenSigningAlg == "" {
+		env.TokenSigningAlg = "RS256"
+	}
+	if env.TokenPrivateKeyPath == "" && viper.GetString("RSA_PRIVATE_KEY_PATH") != "" {
+		log.Println("RSA_PRIVATE_KEY_PATH is deprecated, use TOKEN_PRIVATE_KEY_PATH")
+		env.TokenPrivateKeyPath = viper.GetString("RSA_PRIVATE_KEY_PATH")
+	}
+	if env.TokenPublicKeyPath == "" && viper.GetString("RSA_PUBLIC_KEY_PATH") != "" {
+		log.Println("RSA_PUBLIC_KEY_PATH is deprecated, use TOKEN_PUBLIC_KEY_PATH")
+		env.TokenPublicKeyPath = viper.GetString("RSA_PUBLIC_KEY_PATH")
+	}
+}
+
+func trustedProxies(list string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(list, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
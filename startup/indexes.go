@@ -11,6 +11,8 @@ import (
 func EnsureDbIndexes(db mongo.Database) {
 	go mongo.Document[auth.Keystore](&auth.Keystore{}).EnsureIndexes(db)
 	go mongo.Document[auth.ApiKey](&auth.ApiKey{}).EnsureIndexes(db)
+	go mongo.Document[auth.PasswordReset](&auth.PasswordReset{}).EnsureIndexes(db)
+	go mongo.Document[auth.MFA](&auth.MFA{}).EnsureIndexes(db)
 	go mongo.Document[user.User](&user.User{}).EnsureIndexes(db)
 	go mongo.Document[user.Role](&user.Role{}).EnsureIndexes(db)
 	go mongo.Document[blog.Blog](&blog.Blog{}).EnsureIndexes(db)
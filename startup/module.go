@@ -2,29 +2,47 @@ package startup
 
 import (
 	"context"
+	"log/slog"
+	"time"
 
 	"github.com/unusualcodeorg/goserve/api/auth"
+	"github.com/unusualcodeorg/goserve/api/auth/apikey"
+	"github.com/unusualcodeorg/goserve/api/auth/mfa"
 	authMW "github.com/unusualcodeorg/goserve/api/auth/middleware"
+	authModel "github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/api/auth/session"
 	"github.com/unusualcodeorg/goserve/api/blog"
 	"github.com/unusualcodeorg/goserve/api/blog/author"
 	"github.com/unusualcodeorg/goserve/api/blog/editor"
 	"github.com/unusualcodeorg/goserve/api/blogs"
 	"github.com/unusualcodeorg/goserve/api/contact"
 	"github.com/unusualcodeorg/goserve/api/user"
+	userModel "github.com/unusualcodeorg/goserve/api/user/model"
+	"github.com/unusualcodeorg/goserve/arch/health"
+	"github.com/unusualcodeorg/goserve/arch/mailer"
 	coreMW "github.com/unusualcodeorg/goserve/arch/middleware"
 	"github.com/unusualcodeorg/goserve/arch/mongo"
 	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/ratelimit"
 	"github.com/unusualcodeorg/goserve/arch/redis"
+	"github.com/unusualcodeorg/goserve/common"
 	"github.com/unusualcodeorg/goserve/config"
 )
 
+// api keys issued for the older versions are rejected
+const apiVersion = 1
+
 type Module network.Module[module]
 
 type module struct {
 	Context     context.Context
 	Env         *config.Env
+	Logger      *slog.Logger
 	DB          mongo.Database
 	Store       redis.Store
+	Mailer      mailer.Mailer
+	Health      health.Registry
+	Limiter     ratelimit.Limiter
 	UserService user.Service
 	AuthService auth.Service
 	BlogService blog.Service
@@ -36,42 +54,84 @@ func (m *module) GetInstance() *module {
 
 func (m *module) Controllers() []network.Controller {
 	return []network.Controller{
-		auth.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.AuthService),
-		user.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.UserService),
+		auth.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.RateLimitProvider(), auth.NewRateLimits(m.Env), m.AuthService),
+		apikey.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.PermissionProvider(), m.AuthService),
+		session.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.AuthService),
+		mfa.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.RateLimitProvider(), m.AuthService),
+		user.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.RateLimitProvider(), user.NewRateLimits(m.Env), m.UserService, m.AuthService),
 		blog.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.BlogService),
 		author.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), author.NewService(m.DB, m.BlogService)),
 		editor.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), editor.NewService(m.DB, m.UserService)),
 		blogs.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), blogs.NewService(m.DB, m.Store)),
-		contact.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), contact.NewService(m.DB)),
+		contact.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.RateLimitProvider(), contact.NewRateLimits(m.Env), contact.NewService(m.DB)),
 	}
 }
 
 func (m *module) RootMiddlewares() []network.RootMiddleware {
 	return []network.RootMiddleware{
-		coreMW.NewErrorCatcher(), // NOTE: this should be the first handler to be mounted
+		coreMW.NewRequestId(), // NOTE: this should be the first handler to be mounted
+		coreMW.NewTracing(),
+		coreMW.NewAccessLog(m.Logger, common.ContextLogAttrs), // NOTE: this should wrap all the handlers to log the final status
+		coreMW.NewMetrics(m.Env.MetricsPath, m.Env.MetricsToken),
+		coreMW.NewErrorCatcher(), // NOTE: this should be the first handler after the access log and metrics
+		coreMW.NewHealth(m.Health),
+		coreMW.NewJWKS(m.AuthService.KeySet()),
 		authMW.NewKeyProtection(m.AuthService),
+		authMW.NewKeyPermission(network.ApiPermission{
+			Version:     apiVersion,
+			Permissions: []string{string(authModel.GeneralPermission)},
+		}),
+		coreMW.NewRateLimit(m.Limiter, network.RateLimit{
+			Name:     "api",
+			Requests: m.Env.RateLimitRequests,
+			Window:   time.Duration(m.Env.RateLimitWindowSec) * time.Second,
+			Key:      common.RateLimitByApiKey,
+		}),
 		coreMW.NewNotFound(),
 	}
 }
 
 func (m *module) AuthenticationProvider() network.AuthenticationProvider {
+	if m.Env.TokenStatelessVerification {
+		return authMW.NewStatelessAuthenticationProvider(m.AuthService)
+	}
 	return authMW.NewAuthenticationProvider(m.AuthService, m.UserService)
 }
 
 func (m *module) AuthorizationProvider() network.AuthorizationProvider {
-	return authMW.NewAuthorizationProvider()
+	return authMW.NewAuthorizationProvider(userModel.ParseRoleCodes(m.Env.MFARequiredRoles)...)
+}
+
+func (m *module) PermissionProvider() network.PermissionProvider {
+	return authMW.NewPermissionProvider()
+}
+
+func (m *module) RateLimitProvider() network.RateLimitProvider {
+	return coreMW.NewRateLimitProvider(m.Limiter)
 }
 
-func NewModule(context context.Context, env *config.Env, db mongo.Database, store redis.Store) Module {
+func NewModule(
+	context context.Context,
+	env *config.Env,
+	logger *slog.Logger,
+	db mongo.Database,
+	store redis.Store,
+	mailer mailer.Mailer,
+	health health.Registry,
+) Module {
 	userService := user.NewService(db)
-	authService := auth.NewService(db, env, userService)
+	authService := auth.NewService(db, store, env, logger, mailer, userService)
 	blogService := blog.NewService(db, store, userService)
 
 	return &module{
 		Context:     context,
 		Env:         env,
+		Logger:      logger,
 		DB:          db,
 		Store:       store,
+		Mailer:      mailer,
+		Health:      health,
+		Limiter:     ratelimit.NewRedisLimiter(store),
 		UserService: userService,
 		AuthService: authService,
 		BlogService: blogService,
@@ -5,9 +5,13 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/unusualcodeorg/goserve/arch/health"
+	"github.com/unusualcodeorg/goserve/arch/logger"
+	"github.com/unusualcodeorg/goserve/arch/mailer"
 	"github.com/unusualcodeorg/goserve/arch/mongo"
 	"github.com/unusualcodeorg/goserve/arch/network"
 	"github.com/unusualcodeorg/goserve/arch/redis"
+	"github.com/unusualcodeorg/goserve/arch/tracing"
 	"github.com/unusualcodeorg/goserve/config"
 )
 
@@ -17,12 +21,51 @@ func Server() {
 	env := config.NewEnv(".env", true)
 	router, _, shutdown := create(env)
 	defer shutdown()
-	router.Start(env.ServerHost, env.ServerPort)
+	// blocks till SIGINT/SIGTERM, then drains the requests and runs the shutdown hooks
+	router.Start(env.ServerHost, env.ServerPort, serverOptions(env)...)
+}
+
+const defaultReadHeaderTimeout = 5 * time.Second
+
+func serverOptions(env *config.Env) []network.ServerOption {
+	// the http server would fall back to the read timeout
+	readHeaderTimeout := time.Duration(env.ServerReadHeaderTimeout) * time.Second
+	if readHeaderTimeout == 0 {
+		readHeaderTimeout = defaultReadHeaderTimeout
+	}
+
+	return []network.ServerOption{
+		network.WithReadTimeout(time.Duration(env.ServerReadTimeout) * time.Second),
+		network.WithReadHeaderTimeout(readHeaderTimeout),
+		network.WithWriteTimeout(time.Duration(env.ServerWriteTimeout) * time.Second),
+		network.WithIdleTimeout(time.Duration(env.ServerIdleTimeout) * time.Second),
+		network.WithMaxHeaderBytes(env.ServerMaxHeaderBytes),
+		network.WithTLS(env.TLSCertPath, env.TLSKeyPath),
+		network.WithH2C(env.ServerH2C),
+	}
 }
 
 func create(env *config.Env) (network.Router, Module, Shutdown) {
 	context := context.Background()
 
+	logger := logger.NewLogger(&logger.Config{
+		Format: env.LogFormat,
+		Level:  env.LogLevel,
+	})
+
+	tracingConfig := tracing.Config{
+		Exporter:     env.TracingExporter,
+		FilePath:     env.TracingFilePath,
+		OTLPEndpoint: env.TracingOTLPEndpoint,
+		ServiceName:  env.TracingServiceName,
+		SampleRatio:  env.TracingSampleRatio,
+	}
+
+	tracer, err := tracing.NewProvider(&tracingConfig, logger)
+	if err != nil {
+		panic(err)
+	}
+
 	dbConfig := mongo.DbConfig{
 		User:        env.DBUser,
 		Pwd:         env.DBUserPwd,
@@ -34,13 +77,9 @@ func create(env *config.Env) (network.Router, Module, Shutdown) {
 		Timeout:     time.Duration(env.DBQueryTimeout) * time.Second,
 	}
 
-	db := mongo.NewDatabase(context, dbConfig)
+	db := mongo.NewDatabase(context, dbConfig, logger)
 	db.Connect()
 
-	if env.GoMode != gin.TestMode {
-		EnsureDbIndexes(db)
-	}
-
 	redisConfig := redis.Config{
 		Host: env.RedisHost,
 		Port: env.RedisPort,
@@ -48,20 +87,62 @@ func create(env *config.Env) (network.Router, Module, Shutdown) {
 		DB:   env.RedisDB,
 	}
 
-	store := redis.NewStore(context, &redisConfig)
+	store := redis.NewStore(context, &redisConfig, logger)
 	store.Connect()
 
-	module := NewModule(context, env, db, store)
+	mailerConfig := mailer.Config{
+		Type:    env.Mailer,
+		From:    env.MailerFrom,
+		FileDir: env.MailerFileDir,
+		Host:    env.SMTPHost,
+		Port:    env.SMTPPort,
+		User:    env.SMTPUser,
+		Pwd:     env.SMTPPwd,
+	}
+
+	mailer, err := mailer.NewMailer(&mailerConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	health := health.NewRegistry()
+	health.SetTimeout(time.Duration(env.HealthCheckTimeout) * time.Second)
+	db.RegisterHealthCheck(health)
+	store.RegisterHealthCheck(health)
+
+	module := NewModule(context, env, logger, db, store, mailer, health)
+
+	// plaintext keys must be hashed before the unique hash index is built
+	hashed, err := module.GetInstance().AuthService.HashPlaintextApiKeys()
+	if err != nil {
+		panic(err)
+	}
+	if hashed > 0 {
+		logger.Info("hashed plaintext api keys", "count", hashed)
+	}
+
+	// the ttl index removes only the keystores with an expiry
+	backfilled, err := module.GetInstance().AuthService.BackfillKeystoreExpiry()
+	if err != nil {
+		panic(err)
+	}
+	if backfilled > 0 {
+		logger.Info("set the expiry of the old keystores", "count", backfilled)
+	}
+
+	if env.GoMode != gin.TestMode {
+		EnsureDbIndexes(db)
+	}
 
-	router := network.NewRouter(env.GoMode)
+	router := network.NewRouter(env.GoMode, env.TrustedProxies, logger)
+	router.SetShutdownTimeout(time.Duration(env.ServerShutdownTimeout) * time.Second)
+	router.RegisterDrainHook(health.Drain)
+	router.RegisterShutdownHook(tracer.Shutdown)
+	router.RegisterShutdownHook(db.Disconnect)
+	router.RegisterShutdownHook(store.Disconnect)
 	router.RegisterValidationParsers(network.CustomTagNameFunc())
 	router.LoadRootMiddlewares(module.RootMiddlewares())
 	router.LoadControllers(module.Controllers())
 
-	shutdown := func() {
-		db.Disconnect()
-		store.Disconnect()
-	}
-
-	return router, module, shutdown
+	return router, module, router.Shutdown
 }
@@ -9,8 +9,12 @@ import (
 
 type Teardown = func()
 
-func TestServer() (network.Router, Module, Teardown) {
+// the overrides change the test env before the server is created
+func TestServer(overrides ...func(env *config.Env)) (network.Router, Module, Teardown) {
 	env := config.NewEnv("../.test.env", false)
+	for _, override := range overrides {
+		override(env)
+	}
 	router, module, shutdown := create(env)
 	ts := httptest.NewServer(router.GetEngine())
 	teardown := func() {
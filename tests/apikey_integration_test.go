@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/startup"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIntegrationHashPlaintextApiKeys(t *testing.T) {
+	_, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	collection := module.GetInstance().DB.GetInstance().Collection(model.ApiKeyCollectionName)
+	ctx := context.Background()
+
+	// an api key stored before the keys were hashed
+	id := primitive.NewObjectID()
+	now := time.Now()
+	_, err := collection.InsertOne(ctx, bson.M{
+		"_id":         id,
+		"key":         "legacy_plaintext_key",
+		"version":     1,
+		"permissions": []model.Permission{model.GeneralPermission},
+		"comments":    []string{"comment"},
+		"status":      true,
+		"createdAt":   now,
+		"updatedAt":   now,
+	})
+	if err != nil {
+		t.Fatalf("could not insert apikey: %v", err)
+	}
+	defer collection.DeleteOne(ctx, bson.M{"_id": id})
+
+	hashed, err := module.GetInstance().AuthService.HashPlaintextApiKeys()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hashed)
+
+	apikey, err := module.GetInstance().AuthService.FindApiKey("legacy_plaintext_key")
+	assert.NoError(t, err)
+	assert.Equal(t, id, apikey.ID)
+
+	// a second run has nothing left to migrate
+	hashed, err = module.GetInstance().AuthService.HashPlaintextApiKeys()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, hashed)
+}
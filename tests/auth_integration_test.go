@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/arch/mailer"
 	"github.com/unusualcodeorg/goserve/arch/network"
 	"github.com/unusualcodeorg/goserve/startup"
 )
@@ -16,7 +17,8 @@ func TestIntegrationAuthController_SignupSuccess(t *testing.T) {
 	router, module, shutdown := startup.TestServer()
 	defer shutdown()
 
-	apikey, err := module.GetInstance().AuthService.CreateApiKey("test_key", 1, []model.Permission{"test"}, []string{"comment"})
+	key := "test_key"
+	apikey, err := module.GetInstance().AuthService.CreateApiKey(key, 1, []model.Permission{model.GeneralPermission}, []string{"comment"})
 	if err != nil {
 		t.Fatalf("could not create apikey: %v", err)
 	}
@@ -28,7 +30,7 @@ func TestIntegrationAuthController_SignupSuccess(t *testing.T) {
 		t.Fatalf("could not create request: %v", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set(network.ApiKeyHeader, apikey.Key)
+	req.Header.Set(network.ApiKeyHeader, key)
 
 	rr := httptest.NewRecorder()
 	router.GetEngine().ServeHTTP(rr, req)
@@ -39,6 +41,13 @@ func TestIntegrationAuthController_SignupSuccess(t *testing.T) {
 	assert.Contains(t, rr.Body.String(), `"user"`)
 	assert.Contains(t, rr.Body.String(), `"roles"`)
 	assert.Contains(t, rr.Body.String(), `"tokens"`)
+	assert.Contains(t, rr.Body.String(), `"verified":false`)
+
+	sent := module.GetInstance().Mailer.(mailer.MemoryMailer).Sent()
+	if assert.NotEmpty(t, sent) {
+		assert.Equal(t, "test@abc.com", sent[len(sent)-1].To)
+		assert.Contains(t, sent[len(sent)-1].Body, module.GetInstance().Env.EmailVerificationURL)
+	}
 
 	_, err = module.GetInstance().AuthService.DeleteApiKey(apikey)
 	if err != nil {
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/arch/mailer"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/config"
+	"github.com/unusualcodeorg/goserve/startup"
+)
+
+func TestIntegrationAuthController_SignInLockout(t *testing.T) {
+	router, module, shutdown := startup.TestServer(func(env *config.Env) {
+		env.SignInFreeAttempts = 0
+		env.SignInDelayBaseSec = 0
+		env.SignInLockoutThreshold = 2
+		env.SignInIPLockoutThreshold = 0
+		env.SignInLockoutSec = 60
+	})
+	defer shutdown()
+
+	key := "test_key"
+	apikey, err := module.GetInstance().AuthService.CreateApiKey(key, 1, []model.Permission{model.GeneralPermission}, []string{"comment"})
+	if err != nil {
+		t.Fatalf("could not create apikey: %v", err)
+	}
+
+	send := func(path string, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", path, bytes.NewBuffer([]byte(body)))
+		if err != nil {
+			t.Fatalf("could not create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(network.ApiKeyHeader, key)
+
+		rr := httptest.NewRecorder()
+		router.GetEngine().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send("/auth/signup/basic", `{"email":"lock@abc.com","password":"123456","name":"lock user"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// the unknown emails and the wrong passwords are not told apart
+	rr = send("/auth/signin/basic", `{"email":"unknown-lock@abc.com","password":"123456"}`)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"invalid email or password"`)
+
+	for i := 0; i < 2; i++ {
+		rr = send("/auth/signin/basic", `{"email":"lock@abc.com","password":"wrong-password"}`)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"message":"invalid email or password"`)
+	}
+
+	// the correct password is not checked while locked
+	rr = send("/auth/signin/basic", `{"email":"lock@abc.com","password":"123456"}`)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Contains(t, rr.Body.String(), `account is temporarily locked`)
+	assert.NotEmpty(t, rr.Header().Get(network.RetryAfterHeader))
+
+	unlockURL := module.GetInstance().Env.AccountUnlockURL
+	sent := module.GetInstance().Mailer.(mailer.MemoryMailer).Sent()
+	if !assert.NotEmpty(t, sent) {
+		t.FailNow()
+	}
+	mail := sent[len(sent)-1]
+	assert.Equal(t, "lock@abc.com", mail.To)
+	start := strings.Index(mail.Body, unlockURL)
+	if !assert.GreaterOrEqual(t, start, 0) {
+		t.FailNow()
+	}
+	token := strings.Fields(mail.Body[start+len(unlockURL):])[0]
+
+	rr = send("/auth/unlock", fmt.Sprintf(`{"token":%q}`, token))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = send("/auth/unlock", fmt.Sprintf(`{"token":%q}`, token))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"unlock token already used"`)
+
+	rr = send("/auth/signin/basic", `{"email":"lock@abc.com","password":"123456"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"tokens"`)
+
+	_, err = module.GetInstance().AuthService.DeleteApiKey(apikey)
+	if err != nil {
+		t.Fatalf("could not delete apikey: %v", err)
+	}
+
+	_, err = module.GetInstance().UserService.DeleteUserByEmail("lock@abc.com")
+	if err != nil {
+		t.Fatalf("could not delete user: %v", err)
+	}
+}
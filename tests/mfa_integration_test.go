@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/totp"
+	"github.com/unusualcodeorg/goserve/startup"
+)
+
+func TestIntegrationMFAController_SignIn(t *testing.T) {
+	router, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	key := "test_key"
+	apikey, err := module.GetInstance().AuthService.CreateApiKey(key, 1, []model.Permission{model.GeneralPermission}, []string{"comment"})
+	if err != nil {
+		t.Fatalf("could not create apikey: %v", err)
+	}
+
+	send := func(path string, body string, accessToken string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", path, bytes.NewBuffer([]byte(body)))
+		if err != nil {
+			t.Fatalf("could not create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(network.ApiKeyHeader, key)
+		if accessToken != "" {
+			req.Header.Set(network.AuthorizationHeader, "Bearer "+accessToken)
+		}
+
+		rr := httptest.NewRecorder()
+		router.GetEngine().ServeHTTP(rr, req)
+		return rr
+	}
+
+	var response struct {
+		Data struct {
+			Tokens struct {
+				AccessToken string `json:"accessToken"`
+			} `json:"tokens"`
+			Secret   string   `json:"secret"`
+			Codes    []string `json:"codes"`
+			MFAToken string   `json:"mfaToken"`
+		} `json:"data"`
+	}
+	parse := func(rr *httptest.ResponseRecorder) {
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("could not parse response: %v", err)
+		}
+	}
+
+	rr := send("/auth/signup/basic", `{"email":"mfa@abc.com","password":"123456","name":"mfa user"}`, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	parse(rr)
+	accessToken := response.Data.Tokens.AccessToken
+
+	rr = send("/auth/mfa/totp", "", accessToken)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	parse(rr)
+
+	code, err := totp.Generate(response.Data.Secret, time.Now())
+	if err != nil {
+		t.Fatalf("could not generate code: %v", err)
+	}
+
+	rr = send("/auth/mfa/totp/confirm", fmt.Sprintf(`{"code":%q}`, code), accessToken)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	parse(rr)
+	assert.Len(t, response.Data.Codes, 10)
+	recoveryCode := response.Data.Codes[0]
+
+	rr = send("/auth/signin/basic", `{"email":"mfa@abc.com","password":"123456"}`, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"mfa required"`)
+	assert.NotContains(t, rr.Body.String(), `"tokens"`)
+	parse(rr)
+	mfaToken := response.Data.MFAToken
+
+	// the challenge token is not an access token
+	rr = send("/auth/mfa/totp", "", mfaToken)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	body := fmt.Sprintf(`{"mfaToken":%q,"code":%q}`, mfaToken, recoveryCode)
+	rr = send("/auth/mfa/signin", body, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"tokens"`)
+
+	// the challenge and the recovery code are single use
+	rr = send("/auth/mfa/signin", body, "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	_, err = module.GetInstance().AuthService.DeleteApiKey(apikey)
+	if err != nil {
+		t.Fatalf("could not delete apikey: %v", err)
+	}
+
+	_, err = module.GetInstance().UserService.DeleteUserByEmail("mfa@abc.com")
+	if err != nil {
+		t.Fatalf("could not delete user: %v", err)
+	}
+}
+
+func TestIntegrationMFAController_SignInWrongCodes(t *testing.T) {
+	router, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	key := "test_key"
+	apikey, err := module.GetInstance().AuthService.CreateApiKey(key, 1, []model.Permission{model.GeneralPermission}, []string{"comment"})
+	if err != nil {
+		t.Fatalf("could not create apikey: %v", err)
+	}
+
+	send := func(path string, body string, accessToken string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", path, bytes.NewBuffer([]byte(body)))
+		if err != nil {
+			t.Fatalf("could not create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(network.ApiKeyHeader, key)
+		if accessToken != "" {
+			req.Header.Set(network.AuthorizationHeader, "Bearer "+accessToken)
+		}
+
+		rr := httptest.NewRecorder()
+		router.GetEngine().ServeHTTP(rr, req)
+		return rr
+	}
+
+	var response struct {
+		Data struct {
+			Tokens struct {
+				AccessToken string `json:"accessToken"`
+			} `json:"tokens"`
+			Secret   string   `json:"secret"`
+			Codes    []string `json:"codes"`
+			MFAToken string   `json:"mfaToken"`
+		} `json:"data"`
+	}
+	parse := func(rr *httptest.ResponseRecorder) {
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("could not parse response: %v", err)
+		}
+	}
+
+	rr := send("/auth/signup/basic", `{"email":"mfa-wrong@abc.com","password":"123456","name":"mfa user"}`, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	parse(rr)
+	accessToken := response.Data.Tokens.AccessToken
+
+	rr = send("/auth/mfa/totp", "", accessToken)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	parse(rr)
+
+	code, err := totp.Generate(response.Data.Secret, time.Now())
+	if err != nil {
+		t.Fatalf("could not generate code: %v", err)
+	}
+
+	rr = send("/auth/mfa/totp/confirm", fmt.Sprintf(`{"code":%q}`, code), accessToken)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	parse(rr)
+	recoveryCode := response.Data.Codes[0]
+
+	rr = send("/auth/signin/basic", `{"email":"mfa-wrong@abc.com","password":"123456"}`, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	parse(rr)
+	mfaToken := response.Data.MFAToken
+
+	wrong := fmt.Sprintf(`{"mfaToken":%q,"code":"aaaaa-aaaaa"}`, mfaToken)
+	right := fmt.Sprintf(`{"mfaToken":%q,"code":%q}`, mfaToken, recoveryCode)
+
+	// the wrong codes are counted by the account lockout, the delays start after the free attempts
+	for i := 0; i < 3; i++ {
+		rr = send("/auth/mfa/signin", wrong, "")
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"message":"permission denied: invalid code"`)
+	}
+
+	rr = send("/auth/mfa/signin", right, "")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	// the challenge is revoked after its max attempts
+	time.Sleep(1100 * time.Millisecond)
+	rr = send("/auth/mfa/signin", wrong, "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	time.Sleep(2100 * time.Millisecond)
+	rr = send("/auth/mfa/signin", wrong, "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = send("/auth/mfa/signin", right, "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: invalid mfa token"`)
+
+	_, err = module.GetInstance().AuthService.DeleteApiKey(apikey)
+	if err != nil {
+		t.Fatalf("could not delete apikey: %v", err)
+	}
+
+	_, err = module.GetInstance().UserService.DeleteUserByEmail("mfa-wrong@abc.com")
+	if err != nil {
+		t.Fatalf("could not delete user: %v", err)
+	}
+}
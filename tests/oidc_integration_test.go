@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/oidc"
+	"github.com/unusualcodeorg/goserve/config"
+	"github.com/unusualcodeorg/goserve/startup"
+)
+
+func TestIntegrationAuthController_OIDCSignIn(t *testing.T) {
+	stub := oidc.NewStubServer("client", "secret", oidc.Identity{
+		Subject:       "stub-user",
+		Email:         "oidc@abc.com",
+		EmailVerified: true,
+		Name:          "oidc user",
+	})
+	defer stub.Close()
+
+	router, module, shutdown := startup.TestServer(func(env *config.Env) {
+		env.OIDCProviders = []config.OIDCProvider{{
+			Name:         "stub",
+			Issuer:       stub.URL,
+			ClientID:     stub.ClientID,
+			ClientSecret: stub.ClientSecret,
+			RedirectURL:  "http://localhost:3000/oidc/stub/callback",
+		}}
+	})
+	defer shutdown()
+
+	key := "test_key"
+	apikey, err := module.GetInstance().AuthService.CreateApiKey(key, 1, []model.Permission{model.GeneralPermission}, []string{"comment"})
+	if err != nil {
+		t.Fatalf("could not create apikey: %v", err)
+	}
+
+	req, err := http.NewRequest("GET", "/auth/oidc/stub/authorize", nil)
+	if err != nil {
+		t.Fatalf("could not create request: %v", err)
+	}
+	req.Header.Set(network.ApiKeyHeader, key)
+
+	rr := httptest.NewRecorder()
+	router.GetEngine().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var authorization struct {
+		Data struct {
+			URL string `json:"url"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &authorization); err != nil {
+		t.Fatalf("could not parse response: %v", err)
+	}
+
+	code, state, err := stub.Authorize(authorization.Data.URL)
+	if err != nil {
+		t.Fatalf("could not authorize: %v", err)
+	}
+
+	signIn := func() *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"code":%q,"state":%q}`, code, state)
+		req, err := http.NewRequest("POST", "/auth/oidc/stub/signin", bytes.NewBuffer([]byte(body)))
+		if err != nil {
+			t.Fatalf("could not create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(network.ApiKeyHeader, key)
+
+		rr := httptest.NewRecorder()
+		router.GetEngine().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr = signIn()
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"email":"oidc@abc.com"`)
+	assert.Contains(t, rr.Body.String(), `"verified":true`)
+	assert.Contains(t, rr.Body.String(), `"tokens"`)
+
+	// the state is single use
+	rr = signIn()
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"invalid or expired oidc state"`)
+
+	_, err = module.GetInstance().AuthService.DeleteApiKey(apikey)
+	if err != nil {
+		t.Fatalf("could not delete apikey: %v", err)
+	}
+
+	_, err = module.GetInstance().UserService.DeleteUserByEmail("oidc@abc.com")
+	if err != nil {
+		t.Fatalf("could not delete user: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/arch/password"
+	"github.com/unusualcodeorg/goserve/config"
+	"github.com/unusualcodeorg/goserve/startup"
+)
+
+func TestIntegrationAuthController_PasswordRehash(t *testing.T) {
+	send := func(router network.Router, key string, path string, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", path, bytes.NewBuffer([]byte(body)))
+		if err != nil {
+			t.Fatalf("could not create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(network.ApiKeyHeader, key)
+
+		rr := httptest.NewRecorder()
+		router.GetEngine().ServeHTTP(rr, req)
+		return rr
+	}
+
+	key := "test_key"
+
+	// signed up while the hashes were bcrypt
+	router, module, shutdown := startup.TestServer(func(env *config.Env) {
+		env.PasswordHashAlgorithm = password.AlgorithmBcrypt
+		env.PasswordBcryptCost = 4
+	})
+
+	apikey, err := module.GetInstance().AuthService.CreateApiKey(key, 1, []model.Permission{model.GeneralPermission}, []string{"comment"})
+	if err != nil {
+		t.Fatalf("could not create apikey: %v", err)
+	}
+
+	rr := send(router, key, "/auth/signup/basic", `{"email":"rehash@abc.com","password":"123456","name":"rehash user"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	user, err := module.GetInstance().UserService.FindUserByEmail("rehash@abc.com")
+	if err != nil {
+		t.Fatalf("could not find user: %v", err)
+	}
+	assert.True(t, strings.HasPrefix(*user.Password, "$2a$04$"))
+	shutdown()
+
+	router, module, shutdown = startup.TestServer()
+	defer shutdown()
+
+	rr = send(router, key, "/auth/signin/basic", `{"email":"rehash@abc.com","password":"123456"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"tokens"`)
+
+	user, err = module.GetInstance().UserService.FindUserByEmail("rehash@abc.com")
+	if err != nil {
+		t.Fatalf("could not find user: %v", err)
+	}
+	assert.True(t, strings.HasPrefix(*user.Password, "$argon2id$"))
+
+	// the rehashed password still signs in
+	rr = send(router, key, "/auth/signin/basic", `{"email":"rehash@abc.com","password":"123456"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	_, err = module.GetInstance().AuthService.DeleteApiKey(apikey)
+	if err != nil {
+		t.Fatalf("could not delete apikey: %v", err)
+	}
+
+	_, err = module.GetInstance().UserService.DeleteUserByEmail("rehash@abc.com")
+	if err != nil {
+		t.Fatalf("could not delete user: %v", err)
+	}
+}
+
+func TestIntegrationAuthController_SignupBreachedPassword(t *testing.T) {
+	router, module, shutdown := startup.TestServer(func(env *config.Env) {
+		env.PasswordBreachedListPath = "../config/breached_passwords.txt"
+	})
+	defer shutdown()
+
+	key := "test_key"
+	apikey, err := module.GetInstance().AuthService.CreateApiKey(key, 1, []model.Permission{model.GeneralPermission}, []string{"comment"})
+	if err != nil {
+		t.Fatalf("could not create apikey: %v", err)
+	}
+
+	body := `{"email":"breached@abc.com","password":"Password1","name":"breached user"}`
+	req, err := http.NewRequest("POST", "/auth/signup/basic", bytes.NewBuffer([]byte(body)))
+	if err != nil {
+		t.Fatalf("could not create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(network.ApiKeyHeader, key)
+
+	rr := httptest.NewRecorder()
+	router.GetEngine().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"password is too common, it is found in the breached passwords"`)
+
+	_, err = module.GetInstance().AuthService.DeleteApiKey(apikey)
+	if err != nil {
+		t.Fatalf("could not delete apikey: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unusualcodeorg/goserve/api/auth/model"
+	"github.com/unusualcodeorg/goserve/arch/mailer"
+	"github.com/unusualcodeorg/goserve/arch/network"
+	"github.com/unusualcodeorg/goserve/startup"
+)
+
+func TestIntegrationUserController_Profile(t *testing.T) {
+	router, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	key := "test_key"
+	apikey, err := module.GetInstance().AuthService.CreateApiKey(key, 1, []model.Permission{model.GeneralPermission}, []string{"comment"})
+	if err != nil {
+		t.Fatalf("could not create apikey: %v", err)
+	}
+
+	send := func(method string, path string, body string, accessToken string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, bytes.NewBuffer([]byte(body)))
+		if err != nil {
+			t.Fatalf("could not create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(network.ApiKeyHeader, key)
+		if accessToken != "" {
+			req.Header.Set(network.AuthorizationHeader, "Bearer "+accessToken)
+		}
+
+		rr := httptest.NewRecorder()
+		router.GetEngine().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send("POST", "/auth/signup/basic", `{"email":"profile@abc.com","password":"123456","name":"profile user"}`, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var signup struct {
+		Data struct {
+			Tokens struct {
+				AccessToken string `json:"accessToken"`
+			} `json:"tokens"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &signup); err != nil {
+		t.Fatalf("could not parse response: %v", err)
+	}
+	accessToken := signup.Data.Tokens.AccessToken
+
+	rr = send("PUT", "/profile/mine", `{"name":"new name","profilePicUrl":"https://abc.com/pic.png"}`, accessToken)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"name":"new name"`)
+	assert.Contains(t, rr.Body.String(), `"profilePicUrl":"https://abc.com/pic.png"`)
+
+	rr = send("PUT", "/profile/mine/email", `{"email":"profile-new@abc.com","password":"wrong-password"}`, accessToken)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = send("PUT", "/profile/mine/email", `{"email":"profile-new@abc.com","password":"123456"}`, accessToken)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"email":"profile-new@abc.com"`)
+	assert.Contains(t, rr.Body.String(), `"verified":false`)
+
+	// the old email is notified, the new one gets the verification
+	sent := module.GetInstance().Mailer.(mailer.MemoryMailer).Sent()
+	if assert.GreaterOrEqual(t, len(sent), 2) {
+		assert.Equal(t, "profile@abc.com", sent[len(sent)-2].To)
+		assert.Equal(t, "profile-new@abc.com", sent[len(sent)-1].To)
+	}
+
+	rr = send("DELETE", "/profile/mine", "", accessToken)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"account deactivated"`)
+
+	rr = send("GET", "/profile/mine", "", accessToken)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	// the email of the deactivated account stays registered
+	rr = send("POST", "/auth/signup/basic", `{"email":"profile-new@abc.com","password":"123456","name":"profile user"}`, "")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"email already registered"`)
+
+	_, err = module.GetInstance().AuthService.DeleteApiKey(apikey)
+	if err != nil {
+		t.Fatalf("could not delete apikey: %v", err)
+	}
+
+	_, err = module.GetInstance().UserService.DeleteUserByEmail("profile-new@abc.com")
+	if err != nil {
+		t.Fatalf("could not delete user: %v", err)
+	}
+}